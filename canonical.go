@@ -0,0 +1,174 @@
+package EasyJSON
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/373518155/EasyJSONGo/internal/jsonutil"
+)
+
+var (
+	ErrInvalidNumber   = errors.New("invalid number")
+	ErrInvalidUTF8     = errors.New("invalid UTF-8 string")
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+/*
+返回RFC 8785 JSON Canonicalization Scheme (JCS)规定的规范化JSON
+可用于计算哈希值或者签名:
+   1. JSON对象的字段按字段名的UTF-16编码单元排序
+   2. 数字按ECMAScript的Number.prototype.toString()格式输出
+   3. 字符串只转义必须转义的字符
+   4. 不包含任何空白字符
+如果文档中包含NaN、Inf，非法的UTF-8字符串或者无法表示为JSON的值，返回error
+*/
+func (easyJSON *EasyJSON) Canonical() ([]byte, error) {
+	return appendCanonical(nil, easyJSON.root())
+}
+
+func appendCanonical(dst []byte, value interface{}) ([]byte, error) {
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case string:
+		if !utf8.ValidString(v) {
+			return nil, ErrInvalidUTF8
+		}
+		return appendString(dst, v, escapeOptions{canonical: true}), nil
+	case *orderedMap:
+		keys, err := canonicalKeys(v)
+		if err != nil {
+			return nil, err
+		}
+
+		dst = append(dst, '{')
+		for i, k := range keys {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendString(dst, k, escapeOptions{canonical: true})
+			dst = append(dst, ':')
+			dst, err = appendCanonical(dst, v.m[k])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, '}'), nil
+	case []interface{}:
+		dst = append(dst, '[')
+		for i, elem := range v {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst, err = appendCanonical(dst, elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return append(dst, ']'), nil
+	}
+
+	f, ok := toFloat64(value)
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+	s, err := formatES6Number(f)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, s...), nil
+}
+
+/*
+按UTF-16编码单元对字段名排序
+*/
+func canonicalKeys(m *orderedMap) ([]string, error) {
+	keys := make([]string, len(m.keys))
+	units := make(map[string][]uint16, len(m.keys))
+	for i, k := range m.keys {
+		if !utf8.ValidString(k) {
+			return nil, ErrInvalidUTF8
+		}
+		keys[i] = k
+		units[k] = utf16.Encode([]rune(k))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := units[keys[i]], units[keys[j]]
+		for n := 0; n < len(a) && n < len(b); n++ {
+			if a[n] != b[n] {
+				return a[n] < b[n]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return keys, nil
+}
+
+/*
+把各种数字类型(包括以数字为底层类型的自定义类型)转换为float64
+如果value不是数字，第2个返回值为false
+*/
+func toFloat64(value interface{}) (float64, bool) {
+	return jsonutil.ToFloat64(value)
+}
+
+/*
+按ECMAScript的Number.prototype.toString()格式化数字
+参考: https://262.ecma-international.org/#sec-numeric-types-number-tostring
+*/
+func formatES6Number(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrInvalidNumber
+	}
+	if f == 0 {  // 包括-0
+		return "0", nil
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// 最短的能精确还原f的十进制表示，形如 d.ddddde±xx
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp := e, 0
+	if i := strings.IndexByte(e, 'e'); i >= 0 {
+		mantissa = e[:i]
+		exp, _ = strconv.Atoi(e[i+1:])
+	}
+	digits := strings.Replace(mantissa, ".", "", 1)
+	k := len(digits)
+	n := exp + 1  // 小数点的位置
+
+	var s string
+	switch {
+	case k <= n && n <= 21:
+		s = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		s = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		s = "0." + strings.Repeat("0", -n) + digits
+	default:
+		s = digits[:1]
+		if k > 1 {
+			s += "." + digits[1:]
+		}
+		if n-1 >= 0 {
+			s += "e+" + strconv.Itoa(n-1)
+		} else {
+			s += "e" + strconv.Itoa(n-1)
+		}
+	}
+	return sign + s, nil
+}
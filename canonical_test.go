@@ -0,0 +1,103 @@
+package EasyJSON
+
+import (
+	"math"
+	"testing"
+)
+
+// RFC 8785 附录B
+func TestCanonicalNumbers(t *testing.T) {
+	for _, c := range []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	} {
+		got, err := Array(math.Float64frombits(c.bits)).Canonical()
+		if err != nil || string(got) != "["+c.want+"]" {
+			t.Errorf("%016x: got %s, %v, want %s", c.bits, got, err, c.want)
+		}
+	}
+
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000, 0xfff0000000000000} {
+		if _, err := Array(math.Float64frombits(bits)).Canonical(); err != ErrInvalidNumber {
+			t.Errorf("%016x: got %v, want ErrInvalidNumber", bits, err)
+		}
+	}
+}
+
+// RFC 8785 3.2.3: 按UTF-16编码单元排序，U+1F600排在U+FB33之前
+func TestCanonicalKeyOrder(t *testing.T) {
+	doc, err := Parse(`{
+		"\u20ac": "Euro Sign",
+		"\r": "Carriage Return",
+		"\ufb33": "Hebrew Letter Dalet With Dagesh",
+		"1": "One",
+		"\ud83d\ude00": "Emoji: Grinning Face",
+		"\u0080": "Control",
+		"\u00f6": "Latin Small Letter O With Diaeresis"
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := doc.Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\"," +
+		"\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\"," +
+		"\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	if string(got) != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+// RFC 8785 3.2.2.2
+func TestCanonicalStrings(t *testing.T) {
+	doc, err := Parse(`{"s":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","t":"\b\f\t<>&\u2028"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.SetEscapeHTML(true)
+	doc.SetASCIIOnly(true)
+
+	got, err := doc.Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"s\":\"\u20ac$\\u000f\\nA'B\\\"\\\\\\\\\\\"/\",\"t\":\"\\b\\f\\t<>&\u2028\"}"
+	if string(got) != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	if _, err := Object("a", "\xff").Canonical(); err != ErrInvalidUTF8 {
+		t.Fatalf("got %v, want ErrInvalidUTF8", err)
+	}
+	if _, err := Object("\xff", 1).Canonical(); err != ErrInvalidUTF8 {
+		t.Fatalf("got %v, want ErrInvalidUTF8", err)
+	}
+}
@@ -0,0 +1,51 @@
+package EasyJSON
+
+/*
+深拷贝
+返回的EasyJSON与原来的完全独立，修改其中一个不会影响另一个
+输出选项也会一并拷贝
+*/
+func (easyJSON *EasyJSON) Clone() *EasyJSON {
+	if lazy := easyJSON.lazy; lazy != nil && lazy.parent == nil {
+		// 原始字节不会被修改，可以共享
+		clone := newLazy(lazy.raw)
+		clone.opts = easyJSON.opts
+		return clone
+	}
+
+	clone := &EasyJSON{jsonType: easyJSON.jsonType, opts: easyJSON.opts}
+	clone.setRoot(cloneValue(easyJSON.root()))
+	return clone
+}
+
+/*
+深拷贝底层的数据表示
+JSON对象和JSON数组会逐层拷贝，其它值(数字、字符串等)本身不可变，直接返回
+*/
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *orderedMap:
+		if v == nil {
+			return v
+		}
+		m := &orderedMap{
+			keys: make([]string, len(v.keys)),
+			m:    make(map[string]interface{}, len(v.m)),
+		}
+		copy(m.keys, v.keys)
+		for k, elem := range v.m {
+			m.m[k] = cloneValue(elem)
+		}
+		return m
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		a := make([]interface{}, len(v))
+		for i, elem := range v {
+			a[i] = cloneValue(elem)
+		}
+		return a
+	}
+	return value
+}
@@ -0,0 +1,61 @@
+/*
+由JSON文档生成Go的类型定义
+
+用法:
+   easyjson-gen [-name 类型名] [-package 包名] [JSON文件]
+
+没有指定JSON文件时从标准输入读取，结果输出到标准输出，例如
+   curl -s https://example.com/api/book | easyjson-gen -name Book -package model > book.go
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/373518155/EasyJSONGo"
+)
+
+func main() {
+	name := flag.String("name", "Root", "name of the top-level type")
+	pkg := flag.String("package", "", "emit a package clause with this package name")
+	flag.Parse()
+
+	if err := run(*name, *pkg, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "easyjson-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(name string, pkg string, args []string) error {
+	var input io.Reader = os.Stdin
+	if len(args) > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+	if len(args) == 1 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	// 直接由JSON字节生成，按数字的原文区分int64和float64
+	src, err := EasyJSON.GenerateStructFromJSON(name, data)
+	if err != nil {
+		return err
+	}
+
+	if pkg != "" {
+		fmt.Printf("package %s\n\n", pkg)
+	}
+	_, err = os.Stdout.Write(src)
+	return err
+}
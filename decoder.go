@@ -0,0 +1,121 @@
+package EasyJSON
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// RFC 7464 (JSON Text Sequences)中每条记录前的分隔符
+const RECORD_SEPARATOR = 0x1E
+
+/*
+解析某个文档时的错误
+*/
+type DecodeError struct {
+	Offset int64  // 文档开始的字节偏移量
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+/*
+从io.Reader中依次解析多个JSON文档，支持
+   1. 首尾相接的JSON文档，中间可以有空白，也可以没有，例如 {"a":1}{"b":2}[3]
+   2. RFC 7464 (JSON Text Sequences)，每个文档以0x1E开始，以换行结束
+   3. NDJSON，以及以上几种的混合
+每个文档都必须是JSON对象或JSON数组
+   dec := EasyJSON.NewDecoder(r)
+   for {
+      doc, err := dec.Next()
+      if err == io.EOF {
+         break
+      }
+      ...
+   }
+*/
+type Decoder struct {
+	dec        *json.Decoder
+	start, end int64
+	err        error  // 出错后的Next都返回它
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(&separatorReader{r: r})}
+}
+
+/*
+解析下一个文档
+没有更多文档时返回io.EOF，解析出错时返回*DecodeError，之后不能再继续解析
+*/
+func (decoder *Decoder) Next() (*EasyJSON, error) {
+	if decoder.err != nil {
+		return nil, decoder.err
+	}
+
+	offset := decoder.dec.InputOffset()
+	token, err := decoder.dec.Token()
+	if err == io.EOF {
+		decoder.err = io.EOF
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, decoder.fail(offset, err)
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok || (delim != '{' && delim != '[') {
+		return nil, decoder.fail(offset, ErrInvalidJSONString)
+	}
+	start := decoder.dec.InputOffset() - 1
+
+	root, err := decodeToken(decoder.dec, token)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, decoder.fail(start, err)
+	}
+
+	decoder.start, decoder.end = start, decoder.dec.InputOffset()
+	doc := &EasyJSON{}
+	doc.setRoot(root)
+	return doc, nil
+}
+
+/*
+返回最近一次Next得到的文档在输入中的字节偏移量
+start为第一个字节('{'或'[')的偏移量，end为最后一个字节之后的偏移量
+*/
+func (decoder *Decoder) Span() (start, end int64) {
+	return decoder.start, decoder.end
+}
+
+func (decoder *Decoder) fail(offset int64, err error) error {
+	decoder.err = &DecodeError{Offset: offset, Err: err}
+	return decoder.err
+}
+
+/*
+把RFC 7464的分隔符替换为空格，这样JSON Text Sequences就与首尾相接的JSON文档一样处理
+替换前后长度不变，不影响偏移量
+*/
+type separatorReader struct {
+	r io.Reader
+}
+
+func (sr *separatorReader) Read(p []byte) (int, error) {
+	n, err := sr.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == RECORD_SEPARATOR {
+			p[i] = ' '
+		}
+	}
+	return n, err
+}
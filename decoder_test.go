@@ -0,0 +1,83 @@
+package EasyJSON
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	text := "{\"a\":1}{\"b\":[2]}[3]  \n\x1e{\"c\":\"\x1e\"}\n\x1e[]\n{\"d\":null}\n"
+	text = strings.Replace(text, "\"\x1e\"", `"\u001e"`, 1)
+
+	want := []struct {
+		doc        string
+		start, end int64
+	}{
+		{`{"a":1}`, 0, 7},
+		{`{"b":[2]}`, 7, 16},
+		{`[3]`, 16, 19},
+		{`{"c":"\u001e"}`, 23, 37},
+		{`[]`, 39, 41},
+		{`{"d":null}`, 42, 52},
+	}
+
+	dec := NewDecoder(strings.NewReader(text))
+	for _, w := range want {
+		doc, err := dec.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.String() != w.doc {
+			t.Fatalf("got %s, want %s", doc, w.doc)
+		}
+		start, end := dec.Span()
+		if start != w.start || end != w.end || text[start:end] != w.doc {
+			t.Fatalf("%s: got span %d-%d, want %d-%d", w.doc, start, end, w.start, w.end)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := dec.Next(); err != io.EOF {
+			t.Fatalf("got %v, want io.EOF", err)
+		}
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	for _, c := range []struct {
+		text   string
+		valid  int
+		offset int64
+		err    error
+	}{
+		{`{"a":1} 1`, 1, 7, ErrInvalidJSONString},
+		{`{"a":1}"s"`, 1, 7, ErrInvalidJSONString},
+		{`[1,2] {"a":`, 1, 6, io.ErrUnexpectedEOF},
+		{`{"a":1,}`, 0, 0, nil},
+	} {
+		dec := NewDecoder(strings.NewReader(c.text))
+		for i := 0; i < c.valid; i++ {
+			if _, err := dec.Next(); err != nil {
+				t.Fatalf("%q: %v", c.text, err)
+			}
+		}
+
+		_, err := dec.Next()
+		decodeErr, ok := err.(*DecodeError)
+		if !ok || decodeErr.Offset != c.offset {
+			t.Fatalf("%q: got %#v", c.text, err)
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Fatalf("%q: got %v, want %v", c.text, err, c.err)
+		}
+		if !strings.HasPrefix(err.Error(), "offset ") {
+			t.Fatalf("%q: got %q", c.text, err.Error())
+		}
+
+		// 出错后不能再继续解析
+		if _, again := dec.Next(); again != err {
+			t.Fatalf("%q: got %v after an error", c.text, again)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package EasyJSON
+
+/*
+变化的类型
+*/
+type ChangeKind int
+
+const (
+	CHANGE_ADDED        = ChangeKind(iota + 1)  // 新增了字段或数组元素
+	CHANGE_REMOVED                              // 删除了字段或数组元素
+	CHANGE_MODIFIED                             // 值改变了，类型不变
+	CHANGE_TYPE_CHANGED                         // 值的JSON类型改变了，例如由字符串变为数字
+)
+
+var changeKindNames = map[ChangeKind]string{
+	CHANGE_ADDED:        "added",
+	CHANGE_REMOVED:      "removed",
+	CHANGE_MODIFIED:     "modified",
+	CHANGE_TYPE_CHANGED: "type-changed",
+}
+
+func (kind ChangeKind) String() string {
+	if name, ok := changeKindNames[kind]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+/*
+一处变化
+   Path     -- 发生变化的路径，与Get、Set的path语法相同，""表示最外层
+   Kind     -- 变化的类型
+   OldValue -- 原来的值，CHANGE_ADDED时为nil
+   NewValue -- 新的值，CHANGE_REMOVED时为nil
+JSON对象以map[string]interface{}表示，JSON数组以[]interface{}表示
+*/
+type Change struct {
+	Path     string
+	Kind     ChangeKind
+	OldValue interface{}
+	NewValue interface{}
+}
+
+/*
+比较JSON数组的策略
+*/
+type ArrayDiffStrategy int
+
+const (
+	ARRAY_DIFF_BY_INDEX = ArrayDiffStrategy(iota)  // 按下标逐个比较
+	ARRAY_DIFF_LCS                                 // 按最长公共子序列对齐后比较，适合插入、删除了元素的数组
+	ARRAY_DIFF_BY_KEY                              // 元素都是JSON对象时，按KeyField字段的值对齐后比较
+)
+
+/*
+Diff的选项
+   ArrayStrategy -- 比较JSON数组的策略
+   KeyField      -- ArrayStrategy为ARRAY_DIFF_BY_KEY时，用于对齐数组元素的字段名，例如"id"
+                    如果有元素不是JSON对象或者没有该字段，这个数组按下标比较
+*/
+type DiffOptions struct {
+	ArrayStrategy ArrayDiffStrategy
+	KeyField      string
+}
+
+/*
+比较两个EasyJSON，返回从a到b的所有变化
+JSON数组按下标比较
+*/
+func Diff(a, b *EasyJSON) []Change {
+	return DiffWithOptions(a, b, DiffOptions{})
+}
+
+/*
+比较两个EasyJSON，返回从a到b的所有变化
+变化按a中的顺序排列，b中新增的字段排在后面
+*/
+func DiffWithOptions(a, b *EasyJSON, opts DiffOptions) []Change {
+	var oldRoot, newRoot interface{}
+	if a != nil {
+		oldRoot = a.root()
+	}
+	if b != nil {
+		newRoot = b.root()
+	}
+
+	d := &differ{opts: opts}
+	d.diffValue("", oldRoot, newRoot)
+	return d.changes
+}
+
+type differ struct {
+	opts    DiffOptions
+	changes []Change
+}
+
+func (d *differ) add(path string, kind ChangeKind, oldValue, newValue interface{}) {
+	d.changes = append(d.changes, Change{path, kind, exportValue(oldValue), exportValue(newValue)})
+}
+
+func (d *differ) diffValue(path string, oldValue, newValue interface{}) {
+	oldKind, newKind := kindOf(oldValue), kindOf(newValue)
+	if oldKind != newKind {
+		d.add(path, CHANGE_TYPE_CHANGED, oldValue, newValue)
+		return
+	}
+
+	switch oldKind {
+	case KIND_OBJECT:
+		d.diffObject(path, oldValue.(*orderedMap), newValue.(*orderedMap))
+	case KIND_ARRAY:
+		d.diffArray(path, oldValue.([]interface{}), newValue.([]interface{}))
+	default:
+		if !valueEqual(oldValue, newValue) {
+			d.add(path, CHANGE_MODIFIED, oldValue, newValue)
+		}
+	}
+}
+
+func (d *differ) diffObject(path string, oldMap, newMap *orderedMap) {
+	for _, k := range oldMap.keys {
+		newValue, ok := newMap.get(k)
+		if !ok {
+			d.add(joinPath(path, k), CHANGE_REMOVED, oldMap.m[k], nil)
+			continue
+		}
+		d.diffValue(joinPath(path, k), oldMap.m[k], newValue)
+	}
+	for _, k := range newMap.keys {
+		if _, ok := oldMap.get(k); !ok {
+			d.add(joinPath(path, k), CHANGE_ADDED, nil, newMap.m[k])
+		}
+	}
+}
+
+func (d *differ) diffArray(path string, oldArray, newArray []interface{}) {
+	switch d.opts.ArrayStrategy {
+	case ARRAY_DIFF_LCS:
+		d.diffArrayLCS(path, oldArray, newArray)
+		return
+	case ARRAY_DIFF_BY_KEY:
+		if d.diffArrayByKey(path, oldArray, newArray) {
+			return
+		}
+	}
+	d.diffArrayByIndex(path, oldArray, newArray)
+}
+
+func (d *differ) diffArrayByIndex(path string, oldArray, newArray []interface{}) {
+	for i := 0; i < len(oldArray) || i < len(newArray); i++ {
+		switch {
+		case i >= len(newArray):
+			d.add(indexPath(path, i), CHANGE_REMOVED, oldArray[i], nil)
+		case i >= len(oldArray):
+			d.add(indexPath(path, i), CHANGE_ADDED, nil, newArray[i])
+		default:
+			d.diffValue(indexPath(path, i), oldArray[i], newArray[i])
+		}
+	}
+}
+
+/*
+按最长公共子序列对齐
+不在公共子序列中的元素，a中的记为删除(路径为在a中的下标)，b中的记为新增(路径为在b中的下标)
+*/
+func (d *differ) diffArrayLCS(path string, oldArray, newArray []interface{}) {
+	n, m := len(oldArray), len(newArray)
+
+	// lcs[i][j]为oldArray[i:]与newArray[j:]的最长公共子序列的长度
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if valueEqual(oldArray[i], newArray[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		if valueEqual(oldArray[i], newArray[j]) {
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			d.add(indexPath(path, i), CHANGE_REMOVED, oldArray[i], nil)
+			i++
+		} else {
+			d.add(indexPath(path, j), CHANGE_ADDED, nil, newArray[j])
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		d.add(indexPath(path, i), CHANGE_REMOVED, oldArray[i], nil)
+	}
+	for ; j < m; j++ {
+		d.add(indexPath(path, j), CHANGE_ADDED, nil, newArray[j])
+	}
+}
+
+/*
+按KeyField字段的值对齐
+对齐的元素逐个比较(路径为在b中的下标)，a中多出的记为删除，b中多出的记为新增
+如果有元素不能按KeyField对齐，返回false
+*/
+func (d *differ) diffArrayByKey(path string, oldArray, newArray []interface{}) bool {
+	keyField := d.opts.KeyField
+	oldIndex, ok := indexByKey(oldArray, keyField)
+	if !ok {
+		return false
+	}
+	newIndex, ok := indexByKey(newArray, keyField)
+	if !ok {
+		return false
+	}
+
+	for i, elem := range oldArray {
+		if _, ok := newIndex[keyOf(elem, keyField)]; !ok {
+			d.add(indexPath(path, i), CHANGE_REMOVED, elem, nil)
+		}
+	}
+	for j, elem := range newArray {
+		i, ok := oldIndex[keyOf(elem, keyField)]
+		if !ok {
+			d.add(indexPath(path, j), CHANGE_ADDED, nil, elem)
+			continue
+		}
+		d.diffValue(indexPath(path, j), oldArray[i], elem)
+	}
+	return true
+}
+
+/*
+返回keyField字段的值到下标的映射
+如果有元素不是JSON对象、没有keyField字段或者keyField字段的值重复，返回false
+*/
+func indexByKey(a []interface{}, keyField string) (map[string]int, bool) {
+	index := make(map[string]int, len(a))
+	for i, elem := range a {
+		m, ok := elem.(*orderedMap)
+		if !ok {
+			return nil, false
+		}
+		if _, ok := m.get(keyField); !ok {
+			return nil, false
+		}
+
+		key := keyOf(elem, keyField)
+		if _, ok := index[key]; ok {
+			return nil, false
+		}
+		index[key] = i
+	}
+	return index, true
+}
+
+/*
+把keyField字段的值转换为可以作为map的key的字符串
+数字按数值转换，所以1与1.0是同一个key
+*/
+func keyOf(elem interface{}, keyField string) string {
+	value, _ := elem.(*orderedMap).get(keyField)
+	key, err := appendCanonical(nil, value)
+	if err != nil {
+		return string(appendJSON(nil, value, encodeOptions{sortKeys: true}))
+	}
+	return string(key)
+}
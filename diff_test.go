@@ -0,0 +1,28 @@
+package EasyJSON
+
+import "testing"
+
+func TestDiffPathRoundTrip(t *testing.T) {
+	a, _ := Parse(`{"":1,"a.b":{"c[0]":2},"[x]":[1],"*":3,"\"q":4," s ":5,"plain":{"k":6}}`)
+	b, _ := Parse(`{"":9,"a.b":{"c[0]":8},"[x]":[7],"*":6,"\"q":5," s ":4,"plain":{"k":3}}`)
+
+	changes := Diff(a, b)
+	if len(changes) != 7 {
+		t.Fatalf("expected 7 changes, got %v", changes)
+	}
+	for _, change := range changes {
+		if change.Path == "" {
+			t.Fatalf("change of a field has the root path: %+v", change)
+		}
+		value, err := b.Get(change.Path)
+		if err != nil || !valueEqual(value, change.NewValue) {
+			t.Fatalf("Get(%s): got %v, %v, want %v", change.Path, value, err, change.NewValue)
+		}
+		if err := a.Set(change.Path, change.NewValue); err != nil {
+			t.Fatalf("Set(%s): %v", change.Path, err)
+		}
+	}
+	if !a.Equal(b) {
+		t.Fatalf("got %s, want %s", a, b)
+	}
+}
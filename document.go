@@ -0,0 +1,530 @@
+package EasyJSON
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+保留格式的文档，适合修改人工编辑的配置文件
+Set、Delete、Append只改写被修改的部分，注释、空白、字段顺序等都原样保留
+   doc, _ := EasyJSON.ParseDocument(text, EasyJSON.ParseOptions{AllowComments: true})
+   doc.Set("server.port", 8080)
+   os.WriteFile(name, []byte(doc.String()), 0644)
+新增的值输出为紧凑的JSON，新增的字段沿用同级字段的缩进
+*/
+type Document struct {
+	text string
+	opts ParseOptions
+	json *EasyJSON  // text解析后的结果，修改后重新解析
+}
+
+/*
+opts: 允许的语法，与ParseWithOptions相同
+*/
+func ParseDocument(text string, opts ParseOptions) (*Document, error) {
+	easyJSON, err := ParseWithOptions(text, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{text: text, opts: opts, json: easyJSON}, nil
+}
+
+func (doc *Document) String() string {
+	return doc.text
+}
+
+/*
+返回文档内容对应的EasyJSON
+返回的是拷贝，修改它不会影响Document
+*/
+func (doc *Document) EasyJSON() *EasyJSON {
+	return doc.json.Clone()
+}
+
+func (doc *Document) Get(path string) (interface{}, error) {
+	return doc.json.Get(path)
+}
+
+/*
+设置path处的值，规则与EasyJSON的Set相同
+已有的值被原地替换，新增的字段追加在JSON对象的最后
+*/
+func (doc *Document) Set(path string, value interface{}) error {
+	nameList, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	root, err := doc.parse()
+	if err != nil {
+		return err
+	}
+
+	rendered := renderValue(value)
+	if len(nameList) == 0 {  // 替换整个文档
+		if kind := kindOf(valueEncoder(value)); kind != KIND_OBJECT && kind != KIND_ARRAY {
+			return ErrInvalidArguments
+		}
+		return doc.update(splice{root.start, root.end, rendered})
+	}
+
+	parent, err := root.lookup(nameList[:len(nameList)-1])
+	if err != nil {
+		return err
+	}
+
+	name := nameList[len(nameList)-1]
+	if name[0] == '[' {  // 表明是数组
+		if parent.delim != '[' {
+			return ErrNotAnArray
+		}
+		index, _ := strconv.Atoi(name[1 : len(name)-1])
+		if index < 0 || index >= len(parent.members) {
+			return ErrIndexOutOfBounds
+		}
+		member := parent.members[index]
+		return doc.update(splice{member.value.start, member.value.end, rendered})
+	}
+
+	if parent.delim != '{' {
+		return ErrNotAnObject
+	}
+	if member := parent.field(fieldName(name)); member != nil {
+		return doc.update(splice{member.value.start, member.value.end, rendered})
+	}
+	return doc.update(doc.insertMember(parent, string(appendString(nil, fieldName(name), escapeOptions{}))+": "+rendered)...)
+}
+
+/*
+在path处的JSON数组末尾追加value，path为""时表示最外层
+*/
+func (doc *Document) Append(path string, value interface{}) error {
+	root, err := doc.parse()
+	if err != nil {
+		return err
+	}
+
+	nameList, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	array, err := root.lookup(nameList)
+	if err != nil {
+		return err
+	}
+	if array.delim != '[' {
+		return ErrNotAnArray
+	}
+	return doc.update(doc.insertMember(array, renderValue(value))...)
+}
+
+/*
+删除path处的值
+独占一行(或几行)的字段连同所在的行一起删除，同一行末尾的注释也一起删除
+*/
+func (doc *Document) Delete(path string) error {
+	nameList, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(nameList) == 0 {  // 不能删除最外层
+		return ErrInvalidPath
+	}
+	root, err := doc.parse()
+	if err != nil {
+		return err
+	}
+	parent, err := root.lookup(nameList[:len(nameList)-1])
+	if err != nil {
+		return err
+	}
+
+	name := nameList[len(nameList)-1]
+	index := -1
+	if name[0] == '[' {  // 表明是数组
+		if parent.delim != '[' {
+			return ErrNotAnArray
+		}
+		index, _ = strconv.Atoi(name[1 : len(name)-1])
+		if index < 0 || index >= len(parent.members) {
+			return ErrIndexOutOfBounds
+		}
+	} else {
+		if parent.delim != '{' {
+			return ErrNotAnObject
+		}
+		for i, member := range parent.members {
+			if member.key == fieldName(name) {
+				index = i
+			}
+		}
+		if index < 0 {
+			return ErrFieldNotExists
+		}
+	}
+	return doc.update(doc.removeMember(parent, index)...)
+}
+
+/*
+把text中[start, end)替换为replacement
+*/
+type splice struct {
+	start, end  int
+	replacement string
+}
+
+/*
+执行修改并重新解析，splices不能重叠
+*/
+func (doc *Document) update(splices ...splice) error {
+	sort.Slice(splices, func(i, j int) bool {
+		return splices[i].start < splices[j].start
+	})
+
+	text := doc.text
+	// 从后往前替换，前面的位置不受影响
+	for i := len(splices) - 1; i >= 0; i-- {
+		s := splices[i]
+		text = text[:s.start] + s.replacement + text[s.end:]
+	}
+
+	easyJSON, err := ParseWithOptions(text, doc.opts)
+	if err != nil {
+		return err
+	}
+	doc.text, doc.json = text, easyJSON
+	return nil
+}
+
+/*
+在JSON对象或JSON数组的最后添加一个成员
+*/
+func (doc *Document) insertMember(container *cstNode, member string) []splice {
+	if len(container.members) == 0 {
+		inner := doc.text[container.start+1 : container.end-1]
+		if !strings.Contains(inner, "\n") {
+			return []splice{{container.start + 1, container.end - 1, member}}
+		}
+		indent := lineIndent(doc.text, container.start)
+		return []splice{{container.start + 1, container.start + 1, "\n" + indent + indentUnit(doc.text) + member}}
+	}
+
+	last := container.members[len(container.members)-1]
+
+	// 沿用最后一个成员之前的换行和缩进
+	separator := " "
+	prevEnd := container.start + 1
+	if len(container.members) > 1 {
+		prevEnd = container.members[len(container.members)-2].end()
+	}
+	if before := doc.text[prevEnd:last.start]; strings.Contains(before, "\n") {
+		separator = "\n" + lineIndent(doc.text, last.start)
+	}
+
+	anchor := last.end()
+	pos := anchor
+	if separator != " " {
+		pos = lineTrailerEnd(doc.text, anchor)  // 新成员放在同一行的注释之后
+	}
+
+	if last.comma >= 0 {  // 最后一个成员后面有逗号，新成员也加上逗号
+		return []splice{{pos, pos, separator + member + ","}}
+	}
+	if pos == anchor {
+		return []splice{{anchor, anchor, "," + separator + member}}
+	}
+	return []splice{
+		{anchor, anchor, ","},
+		{pos, pos, separator + member},
+	}
+}
+
+func (doc *Document) removeMember(container *cstNode, index int) []splice {
+	members := container.members
+	member := members[index]
+	end := member.end()
+
+	// 独占一行: 删除整行
+	lineStart := member.start
+	for lineStart > 0 && (doc.text[lineStart-1] == ' ' || doc.text[lineStart-1] == '\t') {
+		lineStart--
+	}
+	lineEnd := skipLineTrailer(doc.text, end)
+	ownLine := (lineStart == 0 || doc.text[lineStart-1] == '\n') && lineEnd > end && doc.text[lineEnd-1] == '\n'
+	if ownLine {
+		splices := []splice{{lineStart, lineEnd, ""}}
+		// 删除的是最后一个成员，并且它后面没有逗号，前一个成员的逗号也要删除
+		if index == len(members)-1 && member.comma < 0 && index > 0 && members[index-1].comma >= 0 {
+			comma := members[index-1].comma
+			splices = append([]splice{{comma, comma + 1, ""}}, splices...)
+		}
+		return splices
+	}
+
+	switch {
+	case index < len(members)-1:
+		return []splice{{member.start, members[index+1].start, ""}}
+	case index > 0:
+		return []splice{{members[index-1].value.end, end, ""}}
+	}
+	return []splice{{member.start, end, ""}}
+}
+
+func renderValue(value interface{}) string {
+	return string(appendJSON(nil, valueEncoder(value), encodeOptions{}))
+}
+
+/*
+返回pos所在行的缩进
+*/
+func lineIndent(text string, pos int) string {
+	start := strings.LastIndexByte(text[:pos], '\n') + 1
+	end := start
+	for end < len(text) && (text[end] == ' ' || text[end] == '\t') {
+		end++
+	}
+	return text[start:end]
+}
+
+/*
+文档中使用的一级缩进，取第一个有缩进的行，默认为两个空格
+*/
+func indentUnit(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+/*
+跳过pos之后同一行的空白和注释，返回行尾(换行之前)的位置
+这一行后面还有其它内容时返回pos
+*/
+func lineTrailerEnd(text string, pos int) int {
+	end := pos
+	for {
+		for end < len(text) && (text[end] == ' ' || text[end] == '\t') {
+			end++
+		}
+		// 同一行中的块注释
+		if !strings.HasPrefix(text[end:], "/*") {
+			break
+		}
+		i := strings.Index(text[end:], "*/")
+		if i < 0 || strings.Contains(text[end:end+i], "\n") {
+			break
+		}
+		end += i + 2
+	}
+	if strings.HasPrefix(text[end:], "//") {
+		if i := strings.IndexByte(text[end:], '\n'); i >= 0 {
+			end += i
+		} else {
+			end = len(text)
+		}
+	}
+
+	switch {
+	case end == len(text) || text[end] == '\n':
+		return end
+	case text[end] == '\r' && end+1 < len(text) && text[end+1] == '\n':
+		return end
+	}
+	return pos
+}
+
+/*
+跳过pos之后同一行的空白和注释，到了行尾时返回换行之后的位置，否则返回pos
+*/
+func skipLineTrailer(text string, pos int) int {
+	end := lineTrailerEnd(text, pos)
+	if end < len(text) && text[end] == '\r' {
+		end++
+	}
+	if end < len(text) && text[end] == '\n' {
+		return end + 1
+	}
+	return pos
+}
+
+/*
+具体语法树的节点，只记录位置
+*/
+type cstNode struct {
+	start, end int
+	delim      byte  // '{'、'['，其它值为0
+	members    []cstMember
+}
+
+type cstMember struct {
+	key   string  // JSON数组的元素为""
+	start int     // 字段名(JSON数组为元素)开始的位置
+	value *cstNode
+	comma int     // 之后的逗号的位置，没有逗号为-1
+}
+
+/*
+成员结束的位置，包括之后的逗号
+*/
+func (member cstMember) end() int {
+	if member.comma >= 0 {
+		return member.comma + 1
+	}
+	return member.value.end
+}
+
+func (node *cstNode) field(name string) *cstMember {
+	var found *cstMember
+	for i := range node.members {
+		if node.members[i].key == name {
+			found = &node.members[i]  // 有重复的字段时取最后一个，与解析的结果一致
+		}
+	}
+	return found
+}
+
+func (node *cstNode) lookup(nameList []string) (*cstNode, error) {
+	for _, name := range nameList {
+		if name[0] == '[' {  // 表明是数组
+			if node.delim != '[' {
+				return nil, ErrNotAnArray
+			}
+			index, _ := strconv.Atoi(name[1 : len(name)-1])
+			if index < 0 || index >= len(node.members) {
+				return nil, ErrIndexOutOfBounds
+			}
+			node = node.members[index].value
+			continue
+		}
+
+		if node.delim != '{' {
+			return nil, ErrNotAnObject
+		}
+		member := node.field(fieldName(name))
+		if member == nil {
+			return nil, ErrFieldNotExists
+		}
+		node = member.value
+	}
+	return node, nil
+}
+
+/*
+分析doc.text得到具体语法树
+doc.text已经通过ParseWithOptions的检查
+*/
+func (doc *Document) parse() (*cstNode, error) {
+	p := &cstParser{text: doc.text, t: &relaxedTranslator{src: doc.text, opts: doc.opts}}
+	p.skip()
+	return p.value()
+}
+
+type cstParser struct {
+	text string
+	pos  int
+	t    *relaxedTranslator  // 用于跳过空白和注释、转换单引号字符串
+}
+
+func (p *cstParser) skip() {
+	p.pos = p.t.skipIgnored(p.pos)
+}
+
+func (p *cstParser) value() (*cstNode, error) {
+	if p.pos >= len(p.text) {
+		return nil, ErrInvalidJSONString
+	}
+
+	node := &cstNode{start: p.pos}
+	switch c := p.text[p.pos]; c {
+	case '{', '[':
+		node.delim = c
+		closing := byte('}')
+		if c == '[' {
+			closing = ']'
+		}
+		p.pos++
+		for {
+			p.skip()
+			if p.pos >= len(p.text) {
+				return nil, ErrInvalidJSONString
+			}
+			if p.text[p.pos] == closing {
+				p.pos++
+				break
+			}
+
+			member := cstMember{start: p.pos, comma: -1}
+			if c == '{' {
+				key, err := p.key()
+				if err != nil {
+					return nil, err
+				}
+				member.key = key
+				p.skip()
+				if p.pos >= len(p.text) || p.text[p.pos] != ':' {
+					return nil, ErrInvalidJSONString
+				}
+				p.pos++
+				p.skip()
+			}
+
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			member.value = value
+			p.skip()
+			if p.pos < len(p.text) && p.text[p.pos] == ',' {
+				member.comma = p.pos
+				p.pos++
+			}
+			node.members = append(node.members, member)
+		}
+	case '"', '\'':
+		if _, err := p.str(); err != nil {
+			return nil, err
+		}
+	default:
+		for p.pos < len(p.text) && !strings.ContainsRune(",:]} \t\r\n/", rune(p.text[p.pos])) {
+			p.pos++
+		}
+	}
+	node.end = p.pos
+	return node, nil
+}
+
+/*
+读取带引号的字符串或不带引号的字段名
+*/
+func (p *cstParser) key() (string, error) {
+	if c := p.text[p.pos]; c == '"' || c == '\'' {
+		return p.str()
+	}
+	start := p.pos
+	for p.pos < len(p.text) && (isIdentifierByte(p.text[p.pos]) || (p.text[p.pos] >= '0' && p.text[p.pos] <= '9')) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", ErrInvalidJSONString
+	}
+	return p.text[start:p.pos], nil
+}
+
+func (p *cstParser) str() (string, error) {
+	p.t.i, p.t.out = p.pos, nil
+	if err := p.t.translateString(p.text[p.pos]); err != nil {
+		return "", err
+	}
+	p.pos = p.t.i
+
+	var s string
+	if err := json.Unmarshal(p.t.out, &s); err != nil {
+		return "", ErrInvalidJSONString
+	}
+	return s, nil
+}
@@ -0,0 +1,145 @@
+package EasyJSON
+
+import "testing"
+
+const documentText = `{
+    // 服务器配置
+    "server": {
+        "host": "localhost", // 主机
+        "port": 80
+    },
+    "tags": ["a", "b"],
+    "list": [
+        1,
+        2, /* 第二个 */
+    ],
+    "empty": {}
+}
+`
+
+func TestDocumentSet(t *testing.T) {
+	opts := ParseOptions{AllowComments: true, AllowTrailingCommas: true}
+	doc, err := ParseDocument(documentText, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		path  string
+		value interface{}
+	}{
+		{"server.port", 8080},
+		{"server.tls", Object("on", true)},
+		{"tags[1]", "c"},
+		{"empty.x", 1},
+		{`["new key"]`, "v"},
+	} {
+		if err := doc.Set(c.path, c.value); err != nil {
+			t.Fatalf("%s: %v", c.path, err)
+		}
+	}
+	if err := doc.Append("list", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Append("tags", "d"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+    // 服务器配置
+    "server": {
+        "host": "localhost", // 主机
+        "port": 8080,
+        "tls": {"on":true}
+    },
+    "tags": ["a", "c", "d"],
+    "list": [
+        1,
+        2, /* 第二个 */
+        3,
+    ],
+    "empty": {"x": 1},
+    "new key": "v"
+}
+`
+	if got := doc.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if v, _ := doc.Get("server.tls.on"); v != true {
+		t.Fatalf("Get: got %v", v)
+	}
+	if !doc.EasyJSON().Equal(doc.json) {
+		t.Fatal("EasyJSON differs from the document")
+	}
+
+	// EasyJSON返回的是拷贝
+	doc.EasyJSON().Set("server.port", 1)
+	if v, _ := doc.Get("server.port"); !valueEqual(v, 8080) {
+		t.Fatalf("got %v", v)
+	}
+}
+
+func TestDocumentDelete(t *testing.T) {
+	doc, _ := ParseDocument(documentText, ParseOptions{AllowComments: true, AllowTrailingCommas: true})
+
+	for _, path := range []string{"server.port", "server.host", "tags[0]", "list[1]", "empty"} {
+		if err := doc.Delete(path); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+
+	want := `{
+    // 服务器配置
+    "server": {
+    },
+    "tags": ["b"],
+    "list": [
+        1,
+    ]
+}
+`
+	if got := doc.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDocumentErrors(t *testing.T) {
+	if _, err := ParseDocument(`{"a":1,}`, ParseOptions{}); err == nil {
+		t.Fatal("expected a syntax error")
+	}
+
+	doc, _ := ParseDocument(`{"a": [1], "b": {"c": 1}}`, ParseOptions{})
+	for _, c := range []struct {
+		err error
+		got error
+	}{
+		{ErrInvalidPath, doc.Set("a[", 1)},
+		{ErrIndexOutOfBounds, doc.Set("a[1]", 1)},
+		{ErrNotAnArray, doc.Set("b[0]", 1)},
+		{ErrNotAnObject, doc.Set("a.x", 1)},
+		{ErrFieldNotExists, doc.Set("x.y", 1)},
+		{ErrInvalidArguments, doc.Set("", 1)},
+		{ErrNotAnArray, doc.Append("b", 1)},
+		{ErrFieldNotExists, doc.Append("x", 1)},
+		{ErrInvalidPath, doc.Delete("")},
+		{ErrFieldNotExists, doc.Delete("b.x")},
+		{ErrIndexOutOfBounds, doc.Delete("a[3]")},
+		{ErrNotAnObject, doc.Delete("a.x")},
+		{ErrNotAnArray, doc.Delete("b[0]")},
+	} {
+		if c.got != c.err {
+			t.Errorf("got %v, want %v", c.got, c.err)
+		}
+	}
+	if got := doc.String(); got != `{"a": [1], "b": {"c": 1}}` {
+		t.Fatalf("document changed by failed operations: %s", got)
+	}
+
+	// 替换整个文档
+	if err := doc.Set("", Array(1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `[1,2]` {
+		t.Fatalf("got %s", got)
+	}
+}
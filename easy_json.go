@@ -3,12 +3,17 @@ package EasyJSON
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"math"
+	"sync"
 	"strings"
 	"strconv"
 	"reflect"
 	"runtime"
 	"fmt"
 	"time"
+
+	"github.com/373518155/EasyJSONGo/internal/jsonutil"
 )
 
 
@@ -46,8 +51,18 @@ type EasyJSON struct {
 	jsonType int  // JSON类型: 对象或是数组
 
 	// 底层的数据表示
-	m map[string] interface{}
+	m *orderedMap
 	a []interface{}
+
+	opts encodeOptions  // 输出JSON字符串时的选项
+
+	frozen bool  // 是否只读，见Freeze
+
+	journal  *journal    // 修改日志，见EnableJournal
+	watchers []*watcher  // 见Watch
+	pending  *[]Change   // Transaction中尚未通知的变化
+
+	lazy *lazyDoc  // 懒解析时的原始数据，完整解析后为nil，见ParseLazy
 }
 
 const (
@@ -95,26 +110,36 @@ func Parse(jsonString string) (*EasyJSON, error) {
 		return nil, ErrInvalidJSONString
 	}
 
-	var data interface{}
-
-	err := json.Unmarshal([]byte(jsonString), &data)
+	// 保留JSON对象字段在源字符串中的顺序
+	data, err := decodeString(jsonString)
 	if err != nil {
 		return nil, err
 	}
 
 	// slog("data[%v]", data)
 
-	if jsonType == JSON_TYPE_OBJECT {
-		return &EasyJSON{jsonType, data.(map[string] interface{}), nil}, nil
-	} else {
-		return &EasyJSON{jsonType, nil, data.([]interface{})}, nil
+	switch data := data.(type) {
+	case *orderedMap:
+		return newObject(data), nil
+	case []interface{}:
+		return newArray(data), nil
 	}
+	return nil, ErrInvalidJSONString
+}
+
+func newObject(m *orderedMap) *EasyJSON {
+	return &EasyJSON{jsonType: JSON_TYPE_OBJECT, m: m}
+}
+
+func newArray(a []interface{}) *EasyJSON {
+	return &EasyJSON{jsonType: JSON_TYPE_ARRAY, a: a}
 }
 
 /**
 生成一个JSON对象
 第0个参数为name，第1个参数为value，第2个参数为name，第3个参数为value，... 依此类推
 name必须为string类型，value可以为任意类型
+字段按参数的先后顺序排列
  */
 func Object(args ...interface{}) *EasyJSON {
 	argsCount := len(args)
@@ -124,18 +149,18 @@ func Object(args ...interface{}) *EasyJSON {
 		return nil
 	}
 
-	m := make(map[string] interface{})
+	m := newOrderedMap()
 	name := ""
 
 	for index, value := range args {
 		if index % 2 == 0 {  // name
 			name = value.(string)
 		} else {  // value
-			m[name] = valueEncoder(value)
+			m.set(name, valueEncoder(value))
 		}
 	}
 
-	return &EasyJSON{JSON_TYPE_OBJECT, m, nil}
+	return newObject(m)
 }
 
 /**
@@ -148,7 +173,7 @@ func Array(args ...interface{}) *EasyJSON {
 		a = append(a, valueEncoder(arg))
 	}
 	// slog("a[%v]", a)
-	return &EasyJSON{JSON_TYPE_ARRAY, nil, a}
+	return newArray(a)
 }
 
 
@@ -165,51 +190,118 @@ func (easyJSON *EasyJSON) GetJSONType() int {
 
 
 /**
-获取底层的JSON数据表示
+设置输出JSON字符串时是否按字段名排序
+默认按字段的插入(或解析)顺序输出
+只读的EasyJSON(见Freeze)不能修改输出选项，这几个Set方法都不起作用，需要时先Clone
+ */
+func (easyJSON *EasyJSON) SetSortKeys(sortKeys bool) {
+	if easyJSON.frozen {
+		return
+	}
+	easyJSON.opts.sortKeys = sortKeys
+}
+
+/**
+设置输出JSON字符串时是否转义HTML专用字符
+为true时<(小于号)、>(大于号)、&(与号)会分别替换为\u003c、\u003e、\u0026，
+输出的JSON可以安全地嵌入到HTML的<script>标签中
+ */
+func (easyJSON *EasyJSON) SetEscapeHTML(escapeHTML bool) {
+	if easyJSON.frozen {
+		return
+	}
+	easyJSON.opts.escape.escapeHTML = escapeHTML
+}
+
+/**
+设置输出JSON字符串时是否只输出ASCII字符
+为true时所有非ASCII字符都会替换为\uXXXX，超出基本多文种平面的字符替换为UTF-16代理对
+ */
+func (easyJSON *EasyJSON) SetASCIIOnly(asciiOnly bool) {
+	if easyJSON.frozen {
+		return
+	}
+	easyJSON.opts.escape.asciiOnly = asciiOnly
+}
+
+/**
+设置输出JSON字符串时是否把/(斜杠)转义为\/
+可以避免字符串中的</script>提前结束<script>标签
+ */
+func (easyJSON *EasyJSON) SetEscapeSlash(escapeSlash bool) {
+	if easyJSON.frozen {
+		return
+	}
+	easyJSON.opts.escape.escapeSlash = escapeSlash
+}
+
+
+/**
+获取JSON数据的Go语言表示
+JSON对象为map[string]interface{}，JSON数组为[]interface{}
+返回的是一份拷贝，修改它不会影响EasyJSON本身
  */
 func (easyJSON *EasyJSON) GetData() interface{}  {
-	jsonType := easyJSON.GetJSONType()
-	if jsonType == JSON_TYPE_OBJECT {
+	return exportValue(easyJSON.root())
+}
+
+/*
+获取底层的数据表示
+ */
+func (easyJSON *EasyJSON) root() interface{} {
+	easyJSON.materialize()
+	if easyJSON.GetJSONType() == JSON_TYPE_OBJECT {
 		return easyJSON.m
-	} else {
-		return easyJSON.a
 	}
+	return easyJSON.a
 }
 
-func (easyJSON *EasyJSON) Get(path string) (interface{}, error)  {
-	nameList := parsePath(path)
-	var value interface{}
-
-	jsonType := easyJSON.GetJSONType()
-	if jsonType == JSON_TYPE_OBJECT {
-		value = easyJSON.m
-	} else {
-		value = easyJSON.a
+/*
+替换底层的数据表示，JSON类型随之改变
+ */
+func (easyJSON *EasyJSON) setRoot(root interface{}) {
+	switch root := root.(type) {
+	case *orderedMap:
+		easyJSON.jsonType, easyJSON.m, easyJSON.a = JSON_TYPE_OBJECT, root, nil
+	case []interface{}:
+		easyJSON.jsonType, easyJSON.m, easyJSON.a = JSON_TYPE_ARRAY, nil, root
 	}
+}
 
+/*
+获取path处的值
+JSON对象以map[string]interface{}返回，JSON数组以[]interface{}返回
+path的语法(Set、Delete、GetRaw等接受path的方法都相同):
+   a.b[0].c   -- 字段名之间用.隔开，数组下标写在中括号中
+   a["b.c"]   -- 字段名中有 . [ ] " 等字符，或者为空、为"*"时，写成中括号括起的JSON字符串
+   ""         -- 整个文档
+格式不正确时返回ErrInvalidPath
+ */
+func (easyJSON *EasyJSON) Get(path string) (interface{}, error)  {
+	value, err := easyJSON.get(path)
+	if err != nil {
+		return nil, err
+	}
+	return exportValue(value), nil
+}
 
-	for _, name := range nameList {
-		ch := name[0]
-		if ch == '[' {  // 表明是数组
-			size := len(name)
-			name = name[1 : size - 1]  // 去除前后中括号
-			index, _ := strconv.Atoi(name)
-
-			a := value.([]interface{})
-			if index >= len(a) {  // 数组越界
-				return nil, ErrIndexOutOfBounds
-			}
-			value = a[index]
-		} else { // 表明是对象
-			m := value.(map[string] interface{})
-			val, ok := m[name]
-			if !ok {
-				return nil, ErrFieldNotExists
-			}
-			value = val
+/*
+获取path处底层的数据表示
+ */
+func (easyJSON *EasyJSON) get(path string) (interface{}, error)  {
+	if easyJSON.lazy != nil {
+		data, err := easyJSON.lazyLookup(path)
+		if err != nil {
+			return nil, err
 		}
+		return decodeBytes(data)
 	}
-	return value, nil
+
+	nameList, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return lookupValue(easyJSON.root(), nameList)
 }
 
 
@@ -224,7 +316,7 @@ func (easyJSON *EasyJSON) Opt(path string, defaultValue interface{}) interface{}
 
 
 func (easyJSON *EasyJSON) Exists(path string) bool {
-	_, err := easyJSON.Get(path)
+	_, err := easyJSON.get(path)
 	return err == nil
 }
 
@@ -340,12 +432,19 @@ func (easyJSON *EasyJSON) OptString(path string, defaultValue string) string {
 }
 
 func (easyJSON *EasyJSON) GetObject(path string) (*EasyJSON, error) {
-	value, err := easyJSON.Get(path)
+	if easyJSON.lazy != nil {
+		return easyJSON.lazyView(path, JSON_TYPE_OBJECT)
+	}
+	value, err := easyJSON.get(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &EasyJSON{JSON_TYPE_OBJECT, value.(map[string] interface{}), nil}, nil
+	m, ok := value.(*orderedMap)
+	if !ok {
+		return nil, ErrNotAnObject
+	}
+	return wrapValue(m, easyJSON).(*EasyJSON), nil
 }
 
 
@@ -360,12 +459,19 @@ func (easyJSON *EasyJSON) OptObject(path string, defaultValue *EasyJSON) *EasyJS
 
 
 func (easyJSON *EasyJSON) GetArray(path string) (*EasyJSON, error) {
-	value, err := easyJSON.Get(path)
+	if easyJSON.lazy != nil {
+		return easyJSON.lazyView(path, JSON_TYPE_ARRAY)
+	}
+	value, err := easyJSON.get(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &EasyJSON{JSON_TYPE_ARRAY, nil, value.([]interface{})}, nil
+	a, ok := value.([]interface{})
+	if !ok {
+		return nil, ErrNotAnArray
+	}
+	return wrapValue(a, easyJSON).(*EasyJSON), nil
 }
 
 func (easyJSON *EasyJSON) OptArray(path string, defaultValue *EasyJSON) *EasyJSON {
@@ -380,15 +486,17 @@ func (easyJSON *EasyJSON) OptArray(path string, defaultValue *EasyJSON) *EasyJSO
 /*
 遍历EasyJSONObect 或 EasyJSONArray
 callback: 回调函数 如果是EasyJSONObect，key的类型为string；如果是EasyJSONArray，key的类型为int；
+EasyJSONObect按字段的插入(或解析)顺序遍历
  */
 func (easyJSON *EasyJSON) Range(callback func(key interface{}, value interface{})) {
+	easyJSON.materialize()
 	if easyJSON.jsonType == JSON_TYPE_OBJECT {
-		for k, v := range easyJSON.m {
-			callback(k, v)
+		for _, k := range easyJSON.m.keys {
+			callback(k, exportValue(easyJSON.m.m[k]))
 		}
 	} else if easyJSON.jsonType == JSON_TYPE_ARRAY {
 		for k, v := range easyJSON.a {
-			callback(k, v)
+			callback(k, exportValue(v))
 		}
 	}
 }
@@ -397,134 +505,306 @@ func (easyJSON *EasyJSON) Range(callback func(key interface{}, value interface{}
 获取EasyJSONObect 或 EasyJSONArray的元素个数
  */
 func (easyJSON *EasyJSON) Length() int {
+	if lazy := easyJSON.lazy; lazy != nil {
+		if easyJSON.jsonType == JSON_TYPE_OBJECT {
+			return len(lazy.keys)
+		}
+		return len(lazy.elems)
+	}
 	if easyJSON.jsonType == JSON_TYPE_OBJECT {
-		return len(easyJSON.m)
+		return easyJSON.m.len()
 	} else if easyJSON.jsonType == JSON_TYPE_ARRAY {
 		return len(easyJSON.a)
 	}
 	return -1;
 }
 
+/*
+设置path处的值，path为""时替换整个文档，这时value必须是JSON对象或JSON数组
+ */
 func (easyJSON *EasyJSON) Set(path string, value interface{}) error  {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
 	value = valueEncoder(value)
 
-	nameList := parsePath(path)
-	var iter interface{}
-
-	jsonType := easyJSON.GetJSONType()
-	if jsonType == JSON_TYPE_OBJECT {
-		iter = easyJSON.m
-	} else {
-		iter = easyJSON.a
+	if !easyJSON.observed() {
+		return easyJSON.set(path, value)
 	}
 
-	nameCount := len(nameList)
-	// slog("nameCount[%v]", nameCount)
-
-	counter := 0
-	for _, name := range nameList {
-		counter++
-		ch := name[0]
-		if ch == '[' {  // 表明是数组
-			size := len(name)
-			name = name[1 : size - 1]  // 去除前后中括号
-			index, _ := strconv.Atoi(name)
-
-			a := iter.([]interface{})
-			if index >= len(a) {  // 数组越界
-				return ErrIndexOutOfBounds
-			}
+	old, err := easyJSON.get(path)
+	existed := err == nil
+	if err := easyJSON.set(path, value); err != nil {
+		return err
+	}
 
-			if counter == nameCount {  // 已经到达path的终点
-				a[index] = value
-				return nil
-			}
+	change := Change{Path: path, Kind: CHANGE_MODIFIED, OldValue: old, NewValue: value}
+	if !existed {
+		change.Kind = CHANGE_ADDED
+	} else if kindOf(old) != kindOf(value) {
+		change.Kind = CHANGE_TYPE_CHANGED
+	}
+	easyJSON.record(change)
+	easyJSON.notify(change)
+	return nil
+}
 
-			// 还需要继续遍历
-			iter = a[index]
-		} else { // 表明是对象
-			m := iter.(map[string] interface{})
-			if counter == nameCount {  // 已经到达path的终点
-				m[name] = value
-				return nil
-			}
+func (easyJSON *EasyJSON) set(path string, value interface{}) error {
+	nameList, err := parsePath(path)
+	if err != nil {
+		return err
+	}
 
-			// 还需要继续遍历
-			val, ok := m[name]
-			if !ok {
-				return ErrFieldNotExists
-			}
-			iter = val
+	// path为""时替换整个文档
+	if len(nameList) == 0 {
+		switch value.(type) {
+		case *orderedMap, []interface{}:
+			easyJSON.setRoot(value)
+			return nil
 		}
+		return ErrInvalidArguments
 	}
+
+	root, err := setValue(easyJSON.root(), nameList, value)
+	if err != nil {
+		return err
+	}
+	easyJSON.setRoot(root)
 	return nil
 }
 
 
 
 func (easyJSON *EasyJSON) Append(path string, value interface{}) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
 	value = valueEncoder(value)
 
-	var iter interface{}
+	if !easyJSON.observed() {
+		return easyJSON.append(path, value)
+	}
 
-	jsonType := easyJSON.GetJSONType()
-	if jsonType == JSON_TYPE_OBJECT {
-		iter = easyJSON.m
-	} else {
-		iter = easyJSON.a
+	var size int
+	if path == "" {
+		if a, ok := easyJSON.root().([]interface{}); ok {
+			size = len(a)
+		}
+	} else if a, err := easyJSON.get(path); err == nil {
+		if a, ok := a.([]interface{}); ok {
+			size = len(a)
+		}
+	}
+	if err := easyJSON.append(path, value); err != nil {
+		return err
+	}
+
+	change := Change{Path: indexPath(path, size), Kind: CHANGE_ADDED, NewValue: value}
+	easyJSON.record(change)
+	easyJSON.notify(change)
+	return nil
+}
+
+func (easyJSON *EasyJSON) append(path string, value interface{}) error {
+	nameList, err := parsePath(path)
+	if err != nil {
+		return err
 	}
 
 	// 如果path为空字符串，表示在最外层进行Append操作
-	if path == "" {
-		if jsonType != JSON_TYPE_ARRAY {
+	if len(nameList) == 0 {
+		if easyJSON.GetJSONType() != JSON_TYPE_ARRAY {
 			return ErrNotAnArray
 		}
 
-		easyJSON.a = append(easyJSON.a, value)
+		easyJSON.a = append(easyJSON.root().([]interface{}), value)
+		return nil
+	}
+
+	root, err := updateValue(easyJSON.root(), nameList, func(parent interface{}, name string) (interface{}, error) {
+		child, err := childValue(parent, name)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := child.([]interface{})
+		if !ok {
+			return nil, ErrNotAnArray
+		}
+		return setChild(parent, name, append(a, value)), nil
+	})
+	if err != nil {
+		return err
+	}
+	easyJSON.setRoot(root)
+	return nil
+}
+
+
+/*
+删除path处的值
+如果是数组元素，后面的元素依次前移
+ */
+func (easyJSON *EasyJSON) Delete(path string) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	nameList, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(nameList) == 0 {  // 不能删除最外层
+		return ErrInvalidPath
+	}
+
+	if !easyJSON.observed() {
+		root, err := deleteValue(easyJSON.root(), nameList)
+		if err != nil {
+			return err
+		}
+		easyJSON.setRoot(root)
 		return nil
 	}
 
-	nameList := parsePath(path)
-	nameCount := len(nameList)
-	// slog("nameCount[%v]", nameCount)
+	old, err := easyJSON.get(path)
+	if err != nil {
+		return err
+	}
+	// 删除前记录，以便找到字段原来的位置
+	change := Change{Path: path, Kind: CHANGE_REMOVED, OldValue: old}
+	easyJSON.record(change)
 
-	counter := 0
+	root, err := deleteValue(easyJSON.root(), nameList)
+	if err != nil {
+		return err
+	}
+	easyJSON.setRoot(root)
+	easyJSON.notify(change)
+	return nil
+}
+
+/*
+删除node中nameList处的值，返回删除后的node
+ */
+func deleteValue(node interface{}, nameList []string) (interface{}, error) {
+	return updateValue(node, nameList, func(parent interface{}, name string) (interface{}, error) {
+		if _, err := childValue(parent, name); err != nil {
+			return nil, err
+		}
+		if name[0] == '[' {  // 表明是数组
+			a := parent.([]interface{})
+			index := nameIndex(name)
+			return append(a[:index], a[index + 1:]...), nil
+		}
+		m := parent.(*orderedMap)
+		m.delete(fieldName(name))
+		return m, nil
+	})
+}
+
+/*
+获取node中nameList处的值，nameList由parsePath得到
+ */
+func lookupValue(node interface{}, nameList []string) (interface{}, error) {
 	for _, name := range nameList {
-		counter++
-		ch := name[0]
-		if ch == '[' {
-			size := len(name)
-			name = name[1 : size - 1]  // 去除前后中括号
-			index, _ := strconv.Atoi(name)
-
-			a := iter.([]interface{})
-			if index >= len(a) {  // 数组越界
-				return ErrIndexOutOfBounds
-			}
+		var err error
+		if node, err = childValue(node, name); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
 
-			if counter == nameCount {  // 已经到达path的终点
-				a[index] = append(a[index].([]interface{}), value)
-				return nil
-			}
+/*
+获取node中name处的值，name为nameList中的一级
+ */
+func childValue(node interface{}, name string) (interface{}, error) {
+	if name[0] == '[' {  // 表明是数组
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, ErrNotAnArray
+		}
+		index := nameIndex(name)
+		if index >= len(a) {  // 数组越界
+			return nil, ErrIndexOutOfBounds
+		}
+		return a[index], nil
+	}
 
-			// 还需要继续遍历
-			iter = a[index]
-		} else {
-			m := iter.(map[string] interface{})
-			if counter == nameCount {  // 已经到达path的终点
-				m[name] = append(m[name].([]interface{}), value)
-				return nil
-			}
+	// 表明是对象
+	m, ok := node.(*orderedMap)
+	if !ok {
+		return nil, ErrNotAnObject
+	}
+	val, ok := m.get(fieldName(name))
+	if !ok {
+		return nil, ErrFieldNotExists
+	}
+	return val, nil
+}
+
+/*
+把child放到node中name处，返回修改后的node
+node的类型要先由childValue等检查过
+ */
+func setChild(node interface{}, name string, child interface{}) interface{} {
+	if name[0] == '[' {
+		a := node.([]interface{})
+		a[nameIndex(name)] = child
+		return a
+	}
+	m := node.(*orderedMap)
+	m.set(fieldName(name), child)
+	return m
+}
+
+/*
+找到nameList的上一级节点，调用update修改它，并把修改后的节点逐级放回原处
+数组追加、插入、删除元素后切片会变化，所以update返回修改后的节点
+返回修改后的node
+ */
+func updateValue(node interface{}, nameList []string, update func(parent interface{}, name string) (interface{}, error)) (interface{}, error) {
+	name := nameList[0]
+	if len(nameList) == 1 {  // 已经到达path的终点
+		return update(node, name)
+	}
+
+	child, err := childValue(node, name)
+	if err != nil {
+		return nil, err
+	}
+	if child, err = updateValue(child, nameList[1:], update); err != nil {
+		return nil, err
+	}
+	return setChild(node, name, child), nil
+}
 
-			// 还需要继续遍历
-			val, ok := m[name]
-			if !ok {
-				return ErrFieldNotExists
+/*
+设置node中nameList处的值，返回修改后的node
+最后一级是对象的字段时可以不存在，是数组下标时必须存在
+ */
+func setValue(node interface{}, nameList []string, value interface{}) (interface{}, error) {
+	return updateValue(node, nameList, func(parent interface{}, name string) (interface{}, error) {
+		if name[0] == '[' {
+			if _, err := childValue(parent, name); err != nil {
+				return nil, err
 			}
-			iter = val
+			return setChild(parent, name, value), nil
 		}
-	}
-	return nil
+		m, ok := parent.(*orderedMap)
+		if !ok {
+			return nil, ErrNotAnObject
+		}
+		m.set(fieldName(name), value)
+		return m, nil
+	})
+}
+
+/*
+数组下标"[n]"中的n
+ */
+func nameIndex(name string) int {
+	index, _ := strconv.Atoi(name[1 : len(name) - 1])  // 去除前后中括号
+	return index
 }
 
 
@@ -539,82 +819,179 @@ json/encode.go源码中的注释
 Array and slice values encode as JSON arrays, except that
 []byte encodes as a base64-encoded string, and a nil slice
 encodes as the null JSON value.
+
+NaN、±Inf输出为null，与AppendJSON相同
  */
 func (easyJSON *EasyJSON) String() string {
-	jsonType := easyJSON.GetJSONType()
-	if jsonType == JSON_TYPE_OBJECT {
-		return toJSONString(easyJSON.m, JSON_TYPE_OBJECT)
-	} else {
-		return toJSONString(easyJSON.a, JSON_TYPE_ARRAY)
-	}
+	buf := getBuffer()
+	*buf = easyJSON.AppendJSON((*buf)[:0])
+	jsonString := string(*buf)
+	putBuffer(buf)
+	return jsonString
 }
 
-func toJSONString(json interface{}, jsonType int) string {
-	jsonString := ""
-	first := true
+/*
+把JSON字符串追加到dst的末尾，返回追加后的切片
+可以反复使用同一个dst，避免每次都分配内存
+NaN、±Inf输出为null，需要报错时使用WriteTo
+ */
+func (easyJSON *EasyJSON) AppendJSON(dst []byte) []byte {
+	return appendJSON(dst, easyJSON.root(), easyJSON.opts)
+}
 
-	if jsonType == JSON_TYPE_OBJECT {
-		jsonString += "{"
-		for k, v := range json.(map[string] interface{}) {
-			// slog("k[%v], v[%v]", k, v)
+/*
+把JSON字符串写入w，实现io.WriterTo接口
+输出时每满writeChunkSize就写入w一次，不会在内存中构造完整的JSON字符串
+返回写入的字节数
+有NaN或±Inf时返回ErrInvalidNumber，出错前已经写入w的部分不会撤回
+ */
+func (easyJSON *EasyJSON) WriteTo(w io.Writer) (int64, error) {
+	buf := getBuffer()
+	e := &jsonEncoder{buf: (*buf)[:0], opts: easyJSON.opts, w: w, strict: true}
+	e.encode(easyJSON.root())
+	e.flush()
+	*buf = e.buf[:0]
+	putBuffer(buf)
+	return e.n, e.err
+}
 
-			if !first {
-				jsonString += ","
-			}
-			first = false
+// 输出JSON字符串时使用的缓冲区
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
 
-			jsonString += `"` + k + `":`
+// 超过这个大小的缓冲区不放回bufferPool，避免长期占用大块内存
+const maxPooledBufferSize = 1 << 20
 
-			if v == nil {
-				jsonString += "null"
-			} else {
-				kind := reflect.TypeOf(v).Kind().String()
-				// slog("kind[%s]", kind)
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
 
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
 
-				if kind == "string" {
-					jsonString += Stringer(v.(string), false)
-				} else if kind == "map" {
-					jsonString += toJSONString(v, JSON_TYPE_OBJECT)
-				} else if kind == "slice" {
-					jsonString += toJSONString(v, JSON_TYPE_ARRAY)
-				} else {
-					jsonString += fmt.Sprintf("%v", v)
-				}
-			}
-		}
-		jsonString += "}"
-	} else {
-		jsonString += "["
-		for _, v := range json.([] interface{}) {
+/*
+输出JSON字符串时的选项
+   sortKeys -- 为true时JSON对象按字段名排序输出，否则按字段的插入(或解析)顺序输出
+   escape   -- 字段名和字符串值的转义选项
+ */
+type encodeOptions struct {
+	sortKeys bool
+	escape   escapeOptions
+}
 
-			if !first {
-				jsonString += ","
-			}
-			first = false
+/*
+把value的JSON表示追加到dst的末尾
+NaN、±Inf不能表示为JSON数字，与JavaScript的JSON.stringify一样输出null
+ */
+func appendJSON(dst []byte, value interface{}, opts encodeOptions) []byte {
+	e := &jsonEncoder{buf: dst, opts: opts}
+	e.encode(value)
+	return e.buf
+}
 
-			if v == nil {
-				jsonString += "null"
-			} else {
-				kind := reflect.TypeOf(v).Kind().String()
-				// slog("kind[%s]", kind)
+// WriteTo每次写入w的大小
+const writeChunkSize = 32 << 10
 
+/*
+输出JSON字符串
+   w      -- 不为nil时，buf满writeChunkSize就写入w并清空
+   n      -- 已经写入w的字节数
+   strict -- 为true时遇到NaN、±Inf设置err，否则输出null
+   err    -- 第一个错误，出错后不再输出
+ */
+type jsonEncoder struct {
+	buf    []byte
+	opts   encodeOptions
+	w      io.Writer
+	n      int64
+	strict bool
+	err    error
+}
 
-				if kind == "string" {
-					jsonString += `"` + v.(string) + `"`
-				} else if kind == "map" {
-					jsonString += toJSONString(v, JSON_TYPE_OBJECT)
-				} else if kind == "slice" {
-					jsonString += toJSONString(v, JSON_TYPE_ARRAY)
-				} else {
-					jsonString += fmt.Sprintf("%v", v)
-				}
+func (e *jsonEncoder) encode(value interface{}) {
+	if e.err != nil {
+		return
+	}
+	if e.w != nil && len(e.buf) >= writeChunkSize {
+		e.flush()
+	}
+
+	switch v := value.(type) {
+	case nil:
+		e.buf = append(e.buf, "null"...)
+	case string:
+		e.buf = appendString(e.buf, v, e.opts.escape)
+	case bool:
+		e.buf = strconv.AppendBool(e.buf, v)
+	case float64:
+		e.encodeFloat(v, 64)
+	case float32:
+		e.encodeFloat(float64(v), 32)
+	case int:
+		e.buf = strconv.AppendInt(e.buf, int64(v), 10)
+	case int64:
+		e.buf = strconv.AppendInt(e.buf, v, 10)
+	case *orderedMap:
+		keys := v.keys
+		if e.opts.sortKeys {
+			keys = v.sortedKeys()
+		}
+
+		e.buf = append(e.buf, '{')
+		for i, k := range keys {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
 			}
+			e.buf = appendString(e.buf, k, e.opts.escape)
+			e.buf = append(e.buf, ':')
+			e.encode(v.m[k])
 		}
-		jsonString += "]"
+		e.buf = append(e.buf, '}')
+	case []interface{}:
+		e.buf = append(e.buf, '[')
+		for i, elem := range v {
+			if i > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.encode(elem)
+		}
+		e.buf = append(e.buf, ']')
+	default:
+		e.buf = fmt.Appendf(e.buf, "%v", value)
 	}
+}
 
-	return jsonString
+func (e *jsonEncoder) encodeFloat(f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if e.strict {
+			e.err = ErrInvalidNumber
+			return
+		}
+		e.buf = append(e.buf, "null"...)
+		return
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, 'g', -1, bitSize)
+}
+
+/*
+把buf写入w
+ */
+func (e *jsonEncoder) flush() {
+	if e.err != nil || len(e.buf) == 0 {
+		return
+	}
+	n, err := e.w.Write(e.buf)
+	e.n += int64(n)
+	e.err = err
+	e.buf = e.buf[:0]
 }
 
 func valueEncoder(val interface{}) interface{}  {
@@ -626,13 +1003,17 @@ func valueEncoder(val interface{}) interface{}  {
 	k := t.Kind()
 
 	// 如果是EasyJSON类型，获取其底层的数据
-	if strings.Contains(t.String(), "EasyJSON") {
-		json := val.(*EasyJSON)
-		if json.GetJSONType() == JSON_TYPE_OBJECT {
-			return json.m
-		} else {
-			return json.a
+	// 只读的EasyJSON需要拷贝，否则修改新的文档时会修改到它
+	if json, ok := val.(*EasyJSON); ok {
+		if json.frozen {
+			return cloneValue(json.root())
 		}
+		return json.root()
+	}
+
+	// 已经是底层的数据表示
+	if m, ok := val.(*orderedMap); ok {
+		return m
 	}
 
 	// 如果是指针，取其指向的值
@@ -650,9 +1031,28 @@ func valueEncoder(val interface{}) interface{}  {
 		return arrayEncoder(val)
 	}
 
+	if k == reflect.Map && t.Key().Kind() == reflect.String {
+		return mapEncoder(val)
+	}
+
 	return val
 }
 
+/*
+Go的map没有顺序，按字段名排序后生成orderedMap
+ */
+func mapEncoder(val interface{}) *orderedMap {
+	m := map[string] interface{}{}
+
+	v := reflect.ValueOf(val)
+	iter := v.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = valueEncoder(iter.Value().Interface())
+	}
+
+	return orderedMapFromMap(m)
+}
+
 func arrayEncoder(val interface{}) []interface{} {
 	a := []interface{}{}
 
@@ -667,8 +1067,11 @@ func arrayEncoder(val interface{}) []interface{} {
 	return a
 }
 
-func structEncoder(val interface{}) map[string] interface{} {
-	m := map[string] interface{}{}
+/*
+字段按在结构体中定义的顺序排列
+ */
+func structEncoder(val interface{}) *orderedMap {
+	m := newOrderedMap()
 
 	t := reflect.TypeOf(val)
 	v := reflect.ValueOf(val)
@@ -686,9 +1089,9 @@ func structEncoder(val interface{}) map[string] interface{} {
 		// slog("fieldValue[%v], fieldTag[%s]", fieldValue, fieldTag)
 
 		if len(fieldTag) > 0 {  // 优先用fieldTag
-			m[fieldTag] = fieldValue
+			m.set(fieldTag, fieldValue)
 		} else {
-			m[fieldName] = fieldValue
+			m.set(fieldName, fieldValue)
 		}
 	}
 
@@ -698,54 +1101,141 @@ func structEncoder(val interface{}) map[string] interface{} {
 
 /*
 分析路径，返回路径切片
+   "a.b[0].c"  -- ["a", "b", "[0]", "c"]
+   "a[\"b.c\"]" -- ["a", "b.c"]，字段名中有 . [ ] " 等字符或者为空时，用中括号括起的JSON字符串表示
+   ""          -- 最外层，返回空的切片
+数组下标保存为"[0]"的形式，字段名见fieldName
+路径格式不正确时返回ErrInvalidPath
+ */
+func parsePath(path string) ([]string, error) {
+	return splitPath(path, false)
+}
+
+/*
+wildcard: 是否允许"[*]"，用于Watch的pathPattern
  */
-func parsePath(path string) []string {
+func splitPath(path string, wildcard bool) ([]string, error) {
 	path = strings.TrimSpace(path)
 	var nameList []string
 
-	snippets := strings.Split(path, ".")
-	for _, snippet := range snippets {
-		size := len(snippet)
-		i := 0
-		j := 0
-		var name string
-		beginChar := snippet[0]
-
-		for {
-			ch := snippet[j]
-			if beginChar == '[' { // 是数组索引
-				if ch == ']' {
-					j++
-					name = snippet[i : j]
-					nameList = append(nameList, name)
-					i = j
-					if i >= size {
-						break
-					}
-					beginChar = snippet[i]
+	for i := 0; i < len(path); {
+		if path[i] == '[' {
+			if i + 1 < len(path) && path[i + 1] == '"' {  // 带引号的字段名
+				end := skipRawString([]byte(path), i + 1)
+				if end < 0 || end >= len(path) || path[end] != ']' {
+					return nil, ErrInvalidPath
 				}
-			} else {  // 是对象字段
-				if j + 1 == size {
-					name = snippet[i : j + 1]
-					nameList = append(nameList, name)
-					break
+				var name string
+				if err := json.Unmarshal([]byte(path[i + 1 : end]), &name); err != nil {
+					return nil, ErrInvalidPath
 				}
-				if ch == '[' {
-					name = snippet[i : j]
-					nameList = append(nameList, name)
-					i = j
-
-					if i >= size {
-						break
-					}
-					beginChar = snippet[i]
+				if name == "*" {  // 与Watch的通配符区分
+					nameList = append(nameList, "\"*")
+				} else {
+					nameList = append(nameList, encodeName(name))
 				}
+				i = end + 1
+				continue
+			}
+
+			// 数组下标
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, ErrInvalidPath
 			}
-			j++
+			index := path[i + 1 : i + end]
+			if wildcard && index == "*" {
+				nameList = append(nameList, "[*]")
+			} else if n, err := strconv.Atoi(index); err == nil && isDigits(index) {
+				nameList = append(nameList, indexPath("", n))
+			} else {
+				return nil, ErrInvalidPath
+			}
+			i += end + 1
+			continue
+		}
+
+		// 对象字段，在开头或者.之后
+		if path[i] == '.' && i > 0 {
+			i++
+		} else if i > 0 {
+			return nil, ErrInvalidPath
+		}
+		end := strings.IndexAny(path[i:], ".[")
+		if end < 0 {
+			end = len(path) - i
 		}
+		if end == 0 {  // 空的字段名
+			return nil, ErrInvalidPath
+		}
+		nameList = append(nameList, encodeName(path[i : i + end]))
+		i += end
+	}
+
+	return nameList, nil
+}
+
+/*
+路径切片中的字段名原样保存，空字段名和以"["、"\""开头的字段名前面加上"\""，与数组下标区分开
+以"["开头的一定是数组下标
+ */
+func encodeName(name string) string {
+	if name == "" || name[0] == '[' || name[0] == '"' {
+		return "\"" + name
 	}
+	return name
+}
+
+/*
+路径切片中字段名的实际值，与encodeName相反
+ */
+func fieldName(name string) string {
+	if name[0] == '"' {
+		return name[1:]
+	}
+	return name
+}
 
-	return nameList
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+生成路径，与parsePath相反
+   joinPath("a.b", "c")   返回 "a.b.c"
+   joinPath("a.b", "c.d") 返回 "a.b[\"c.d\"]"
+   indexPath("a.b", 2)    返回 "a.b[2]"
+ */
+func joinPath(parent string, name string) string {
+	return jsonutil.JoinPath(parent, name)
+}
+
+func indexPath(parent string, index int) string {
+	return jsonutil.IndexPath(parent, index)
+}
+
+/*
+由parsePath得到的nameList生成path，与parsePath相反
+同一个位置的不同写法(例如 a.b 和 a["b"])得到相同的path
+ */
+func formatPath(nameList []string) string {
+	path := ""
+	for _, name := range nameList {
+		if name[0] == '[' {
+			path += name
+		} else {
+			path = joinPath(path, fieldName(name))
+		}
+	}
+	return path
 }
 
 /*
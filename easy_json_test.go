@@ -0,0 +1,95 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// 记录每次Write的大小
+type chunkWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestWriteToChunks(t *testing.T) {
+	doc := Array()
+	for i := 0; i < 10000; i++ {
+		doc.Append("", Object("id", i, "name", strings.Repeat("x", 20)))
+	}
+
+	w := &chunkWriter{}
+	n, err := doc.WriteTo(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != doc.String() || n != int64(w.Len()) {
+		t.Fatalf("wrote %d bytes, output differs from String()", n)
+	}
+	if len(w.writes) < 2 {
+		t.Fatalf("expected several writes, got %v", w.writes)
+	}
+	for _, size := range w.writes {
+		if size > 2*writeChunkSize {
+			t.Fatalf("write of %d bytes is not chunked", size)
+		}
+	}
+}
+
+func TestWriteToNonFinite(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		doc := Object("a", 1, "b", f)
+
+		var buf bytes.Buffer
+		if _, err := doc.WriteTo(&buf); err != ErrInvalidNumber {
+			t.Fatalf("%v: got %v, want ErrInvalidNumber", f, err)
+		}
+		if got := doc.String(); got != `{"a":1,"b":null}` {
+			t.Fatalf("%v: got %s", f, got)
+		}
+	}
+
+	if _, err := Array(float32(math.Inf(1))).WriteTo(&bytes.Buffer{}); err != ErrInvalidNumber {
+		t.Fatalf("float32: got %v, want ErrInvalidNumber", err)
+	}
+}
+
+func TestViewKeepsOptions(t *testing.T) {
+	text := []byte(`{"o":{"b":1,"a":"<"},"l":[{"d":1,"c":2}]}`)
+	eager, _ := Parse(string(text))
+	lazy, _ := ParseLazy(text)
+
+	for _, doc := range []*EasyJSON{eager, lazy} {
+		doc.SetSortKeys(true)
+		doc.SetEscapeHTML(true)
+
+		object, _ := doc.GetObject("o")
+		if got := object.String(); got != `{"a":"\u003c","b":1}` {
+			t.Errorf("GetObject: got %s", got)
+		}
+		list, _ := doc.GetArray("l")
+		if got := list.String(); got != `[{"c":2,"d":1}]` {
+			t.Errorf("GetArray: got %s", got)
+		}
+
+		doc.Walk(func(path string, value interface{}, kind Kind) WalkAction {
+			if path == "l[0]" {
+				if got := value.(*EasyJSON).String(); got != `{"c":2,"d":1}` {
+					t.Errorf("Walk: got %s", got)
+				}
+			}
+			return WALK_CONTINUE
+		})
+		for _, value := range list.Elements() {
+			if got := value.(*EasyJSON).String(); got != `{"c":2,"d":1}` {
+				t.Errorf("Elements: got %s", got)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package EasyJSON
+
+import (
+	"math"
+	"reflect"
+)
+
+/*
+判断两个EasyJSON是否表示相同的JSON
+   1. JSON对象的字段顺序不影响比较结果
+   2. 数字按数值比较，不区分类型，例如int的1与float64的1.0相等
+*/
+func (easyJSON *EasyJSON) Equal(other *EasyJSON) bool {
+	if easyJSON == nil || other == nil {
+		return easyJSON == other
+	}
+	if easyJSON.jsonType != other.jsonType {
+		return false
+	}
+	return valueEqual(easyJSON.root(), other.root())
+}
+
+func valueEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	switch x := a.(type) {
+	case *orderedMap:
+		y, ok := b.(*orderedMap)
+		if !ok || x.len() != y.len() {
+			return false
+		}
+		for _, k := range x.keys {
+			elem, ok := y.get(k)
+			if !ok || !valueEqual(x.m[k], elem) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !valueEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if isNumber(a) && isNumber(b) {
+		return numberEqual(a, b)
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.String && vb.Kind() == reflect.String {
+		return va.String() == vb.String()
+	}
+	if va.Kind() == reflect.Bool && vb.Kind() == reflect.Bool {
+		return va.Bool() == vb.Bool()
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+/*
+判断是否为数字(包括以数字为底层类型的自定义类型)
+*/
+func isNumber(value interface{}) bool {
+	_, ok := toFloat64(value)
+	return ok
+}
+
+/*
+按数值比较两个数字
+整数不转换为float64，避免丢失精度，例如int64的1<<53+1与float64的1<<53不相等
+*/
+func numberEqual(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	switch {
+	case isSignedKind(va.Kind()) && isSignedKind(vb.Kind()):
+		return va.Int() == vb.Int()
+	case isUnsignedKind(va.Kind()) && isUnsignedKind(vb.Kind()):
+		return va.Uint() == vb.Uint()
+	case isSignedKind(va.Kind()) && isUnsignedKind(vb.Kind()):
+		return va.Int() >= 0 && uint64(va.Int()) == vb.Uint()
+	case isUnsignedKind(va.Kind()) && isSignedKind(vb.Kind()):
+		return vb.Int() >= 0 && va.Uint() == uint64(vb.Int())
+	case isFloatKind(va.Kind()) && isFloatKind(vb.Kind()):
+		return va.Float() == vb.Float()
+	case isFloatKind(va.Kind()):
+		return floatIntegerEqual(va.Float(), vb)
+	}
+	return floatIntegerEqual(vb.Float(), va)
+}
+
+/*
+比较浮点数f与整数v
+f是整数并且在v的类型范围内时，转换为整数再比较
+*/
+func floatIntegerEqual(f float64, v reflect.Value) bool {
+	if math.IsNaN(f) || f != math.Trunc(f) {
+		return false
+	}
+	if isSignedKind(v.Kind()) {
+		if f < -(1 << 63) || f >= 1 << 63 {
+			return false
+		}
+		return int64(f) == v.Int()
+	}
+	if f < 0 || f >= 1 << 64 {
+		return false
+	}
+	return uint64(f) == v.Uint()
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUnsignedKind(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloatKind(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
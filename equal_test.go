@@ -0,0 +1,34 @@
+package EasyJSON
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumberEqual(t *testing.T) {
+	for _, c := range []struct {
+		a, b  interface{}
+		equal bool
+	}{
+		{1, 1.0, true},
+		{uint8(3), float32(3), true},
+		{int64(-2), -2.0, true},
+		{int64(1<<53 + 1), float64(1 << 53), false},
+		{uint64(1<<64 - 1), float64(1 << 64), false},
+		{int64(math.MaxInt64), float64(1 << 63), false},
+		{int64(math.MinInt64), float64(-1 << 63), true},
+		{uint64(1 << 63), float64(1 << 63), true},
+		{-1, uint64(1<<64 - 1), false},
+		{1, 1.5, false},
+		{0, math.NaN(), false},
+		{int64(math.MaxInt64), math.Inf(1), false},
+		{0.1, 0.1, true},
+	} {
+		if got := numberEqual(c.a, c.b); got != c.equal {
+			t.Errorf("numberEqual(%T %v, %T %v) = %v", c.a, c.a, c.b, c.b, got)
+		}
+		if got := numberEqual(c.b, c.a); got != c.equal {
+			t.Errorf("numberEqual(%T %v, %T %v) = %v", c.b, c.b, c.a, c.a, got)
+		}
+	}
+}
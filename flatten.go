@@ -0,0 +1,288 @@
+package EasyJSON
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/373518155/EasyJSONGo/internal/jsonutil"
+)
+
+var ErrInvalidPath = errors.New("invalid path")
+
+/*
+数组下标的表示方式
+*/
+type IndexStyle int
+
+const (
+	INDEX_STYLE_BRACKET = IndexStyle(iota)  // a.b[0]，与Get、Set的path语法相同
+	INDEX_STYLE_DOTTED                      // a.b.0，Unflatten时全部由数字组成的部分都当作数组下标
+)
+
+/*
+Flatten、Unflatten的选项
+   Separator  -- 各级字段名之间的分隔符，默认为"."
+   IndexStyle -- 数组下标的表示方式
+*/
+type FlattenOptions struct {
+	Separator  string
+	IndexStyle IndexStyle
+}
+
+func (opts FlattenOptions) separator() string {
+	if opts.Separator == "" {
+		return "."
+	}
+	return opts.Separator
+}
+
+/*
+在path后面加上字段名key
+默认选项时与joinPath相同，展开的路径可以直接传给Get、Set
+其它选项时，字段名为空、含有分隔符、中括号或引号，
+或者IndexStyle为INDEX_STYLE_DOTTED时全部由数字组成，用中括号括起的JSON字符串表示
+*/
+func (opts FlattenOptions) joinKey(path string, key string) string {
+	sep := opts.separator()
+	if sep == "." && opts.IndexStyle == INDEX_STYLE_BRACKET {
+		return joinPath(path, key)
+	}
+	if key == "" || strings.Contains(key, sep) || strings.ContainsAny(key, "[]\"") ||
+		(opts.IndexStyle == INDEX_STYLE_DOTTED && isDigits(key)) {
+		return path + "[" + jsonutil.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + sep + key
+}
+
+/*
+把文档展开为一层，路径与Get、Set的path语法相同，例如
+   {"a":{"b":[1,2]}} 展开为 {"a.b[0]":1, "a.b[1]":2}
+   {"a.b":{"c[0]":1}} 展开为 {"[\"a.b\"][\"c[0]\"]":1}
+空的JSON对象和JSON数组保留为map[string]interface{}{}和[]interface{}{}
+字段名中有分隔符、中括号、引号等会与路径混淆的字符时，写成中括号括起的JSON字符串，
+Unflatten时能还原为原来的字段名
+*/
+func (easyJSON *EasyJSON) Flatten() map[string]interface{} {
+	return easyJSON.FlattenWithOptions(FlattenOptions{})
+}
+
+func (easyJSON *EasyJSON) FlattenWithOptions(opts FlattenOptions) map[string]interface{} {
+	flat := make(map[string]interface{})
+	flattenValue(flat, "", easyJSON.root(), opts)
+	return flat
+}
+
+func flattenValue(flat map[string]interface{}, path string, value interface{}, opts FlattenOptions) {
+	switch v := value.(type) {
+	case *orderedMap:
+		if v.len() == 0 && path != "" {
+			flat[path] = map[string]interface{}{}
+			return
+		}
+		for _, k := range v.keys {
+			flattenValue(flat, opts.joinKey(path, k), v.m[k], opts)
+		}
+	case []interface{}:
+		if len(v) == 0 && path != "" {
+			flat[path] = []interface{}{}
+			return
+		}
+		for i, elem := range v {
+			var name string
+			if opts.IndexStyle == INDEX_STYLE_DOTTED {
+				name = strconv.Itoa(i)
+				if path != "" {
+					name = path + opts.separator() + name
+				}
+			} else {
+				name = indexPath(path, i)
+			}
+			flattenValue(flat, name, elem, opts)
+		}
+	default:
+		flat[path] = value
+	}
+}
+
+/*
+由Flatten展开的结果重建文档，与Flatten相反
+如果第一级是数组下标，返回JSON数组，否则返回JSON对象
+数组中没有出现的下标填充为null，下标不能大于等于flat的元素个数
+如果路径有冲突(例如同时有"a"和"a.b")或者格式不正确，返回ErrInvalidPath
+*/
+func Unflatten(flat map[string]interface{}) (*EasyJSON, error) {
+	return UnflattenWithOptions(flat, FlattenOptions{})
+}
+
+func UnflattenWithOptions(flat map[string]interface{}, opts FlattenOptions) (*EasyJSON, error) {
+	paths := make([]string, 0, len(flat))
+	for path := range flat {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	u := &unflattener{assigned: make(map[string]bool), maxIndex: len(flat) - 1}
+	var root interface{}
+	for _, path := range paths {
+		segments, err := splitFlatPath(path, opts)
+		if err != nil {
+			return nil, err
+		}
+		if root, err = u.set(root, segments, valueEncoder(flat[path]), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	switch root := root.(type) {
+	case nil:
+		return newObject(newOrderedMap()), nil
+	case *orderedMap:
+		return newObject(root), nil
+	case []interface{}:
+		return newArray(root), nil
+	}
+	return nil, ErrInvalidPath
+}
+
+/*
+路径中的一级
+   index >= 0 时为数组下标，否则为字段名name
+*/
+type flatSegment struct {
+	name  string
+	index int
+}
+
+/*
+分析Flatten展开的路径
+各级之间用分隔符隔开，每一级是字段名，后面可以跟若干个中括号，中括号中是数组下标或者JSON字符串表示的字段名
+IndexStyle为INDEX_STYLE_DOTTED时，全部由数字组成的字段名当作数组下标
+*/
+func splitFlatPath(path string, opts FlattenOptions) ([]flatSegment, error) {
+	sep := opts.separator()
+	var segments []flatSegment
+	for {
+		end := len(path)
+		if i := strings.Index(path, sep); i >= 0 {
+			end = i
+		}
+		if i := strings.IndexByte(path[:end], '['); i >= 0 {
+			end = i
+		}
+		name := path[:end]
+		path = path[end:]
+		if name != "" || !strings.HasPrefix(path, "[") {
+			if opts.IndexStyle == INDEX_STYLE_DOTTED && isDigits(name) {
+				index, err := strconv.Atoi(name)
+				if err != nil {
+					return nil, ErrInvalidPath
+				}
+				segments = append(segments, flatSegment{index: index})
+			} else {
+				segments = append(segments, flatSegment{name: name, index: -1})
+			}
+		}
+
+		// 形如 [0][1]、["b.c"]
+		for strings.HasPrefix(path, "[") {
+			if len(path) > 1 && path[1] == '"' {
+				end := skipRawString([]byte(path), 1)
+				if end < 0 || end >= len(path) || path[end] != ']' {
+					return nil, ErrInvalidPath
+				}
+				key, err := rawKey([]byte(path[1:end]))
+				if err != nil {
+					return nil, ErrInvalidPath
+				}
+				segments = append(segments, flatSegment{name: key, index: -1})
+				path = path[end+1:]
+				continue
+			}
+
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil, ErrInvalidPath
+			}
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil || index < 0 {
+				return nil, ErrInvalidPath
+			}
+			segments = append(segments, flatSegment{index: index})
+			path = path[end+1:]
+		}
+
+		if path == "" {
+			return segments, nil
+		}
+		if !strings.HasPrefix(path, sep) {
+			return nil, ErrInvalidPath
+		}
+		path = path[len(sep):]
+	}
+}
+
+type unflattener struct {
+	assigned map[string]bool  // 已经设置了值的位置，值为null时也能发现冲突
+	maxIndex int              // 允许的最大数组下标，避免很大的下标分配过多的内存
+}
+
+/*
+把value放到node中segments指定的位置，返回修改后的node
+node为nil时按segments的第一级创建JSON对象或JSON数组
+key: node的位置，由各级的字段名和下标组成，用于记录已经设置了值的位置
+*/
+func (u *unflattener) set(node interface{}, segments []flatSegment, value interface{}, key string) (interface{}, error) {
+	if u.assigned[key] {  // 这里或上一级已经设置了值
+		return nil, ErrInvalidPath
+	}
+	if len(segments) == 0 {
+		if node != nil {  // 已经设置了下一级的值
+			return nil, ErrInvalidPath
+		}
+		u.assigned[key] = true
+		return value, nil
+	}
+
+	segment := segments[0]
+	if segment.index >= 0 {
+		if segment.index > u.maxIndex {
+			return nil, ErrInvalidPath
+		}
+		if node == nil {
+			node = []interface{}{}
+		}
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, ErrInvalidPath
+		}
+		for len(a) <= segment.index {
+			a = append(a, nil)
+		}
+		child, err := u.set(a[segment.index], segments[1:], value, indexPath(key, segment.index))
+		if err != nil {
+			return nil, err
+		}
+		a[segment.index] = child
+		return a, nil
+	}
+
+	if node == nil {
+		node = newOrderedMap()
+	}
+	m, ok := node.(*orderedMap)
+	if !ok {
+		return nil, ErrInvalidPath
+	}
+	old, _ := m.get(segment.name)
+	child, err := u.set(old, segments[1:], value, key+"."+strconv.Quote(segment.name))
+	if err != nil {
+		return nil, err
+	}
+	m.set(segment.name, child)
+	return m, nil
+}
@@ -0,0 +1,77 @@
+package EasyJSON
+
+import "testing"
+
+func TestFlattenRoundTrip(t *testing.T) {
+	doc, _ := Parse(`{"a":{"b":[1,{"c":null}],"d":{}},"e":[]}`)
+	for _, opts := range []FlattenOptions{{}, {Separator: "/", IndexStyle: INDEX_STYLE_DOTTED}} {
+		back, err := UnflattenWithOptions(doc.FlattenWithOptions(opts), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Equal(doc) {
+			t.Fatalf("%+v: got %s", opts, back)
+		}
+	}
+}
+
+func TestFlattenSpecialKeys(t *testing.T) {
+	doc, _ := Parse(`{"a.b":1,"x[0]":2,"":{"*":3},"q\"":[{"0":4," s ":5}],"a/b":{"1":6}}`)
+
+	flat := doc.Flatten()
+	if !valueEqual(flat[`["a.b"]`], 1) || !valueEqual(flat[`["x[0]"]`], 2) {
+		t.Fatalf("keys not quoted: %v", flat)
+	}
+	// 默认选项展开的路径可以直接传给Get
+	for path, value := range flat {
+		if got, err := doc.Get(path); err != nil || !valueEqual(got, value) {
+			t.Errorf("Get(%s): got %v, %v, want %v", path, got, err, value)
+		}
+	}
+
+	for _, opts := range []FlattenOptions{
+		{},
+		{Separator: "/"},
+		{IndexStyle: INDEX_STYLE_DOTTED},
+		{Separator: "/", IndexStyle: INDEX_STYLE_DOTTED},
+	} {
+		back, err := UnflattenWithOptions(doc.FlattenWithOptions(opts), opts)
+		if err != nil {
+			t.Fatalf("%+v: %v", opts, err)
+		}
+		if !back.Equal(doc) {
+			t.Fatalf("%+v: got %s, want %s", opts, back, doc)
+		}
+	}
+}
+
+func TestUnflattenConflict(t *testing.T) {
+	for _, flat := range []map[string]interface{}{
+		{"a": nil, "a.b": 1},
+		{"a": 1, "a.b": 1},
+		{"a": []interface{}{}, "a[0]": 1},
+		{"a[0]": nil, "a[0].b": 1},
+		{"a.b": 1, "a[0]": 1},
+	} {
+		// 多次执行，结果不能依赖map的遍历顺序
+		for i := 0; i < 10; i++ {
+			if _, err := Unflatten(flat); err != ErrInvalidPath {
+				t.Fatalf("%v: got %v, want ErrInvalidPath", flat, err)
+			}
+		}
+	}
+}
+
+func TestUnflattenIndexBound(t *testing.T) {
+	if _, err := Unflatten(map[string]interface{}{"a[100000000]": 1}); err != ErrInvalidPath {
+		t.Fatalf("got %v, want ErrInvalidPath", err)
+	}
+
+	doc, err := Unflatten(map[string]interface{}{"a[2]": 1, "b": 2, "c": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":[null,null,1],"b":2,"c":3}` {
+		t.Fatalf("got %s", got)
+	}
+}
@@ -0,0 +1,120 @@
+package EasyJSON
+
+import (
+	"errors"
+	"strconv"
+)
+
+var ErrImmutable = errors.New("EasyJSON is immutable")
+
+/*
+返回只读的EasyJSON
+只读的EasyJSON不能修改，Set、Append、Delete、ApplyPatch、MergePatch、Merge都返回ErrImmutable
+GetObject、GetArray返回的视图也是只读的
+SetSortKeys等输出选项、Watch、EnableJournal对只读的EasyJSON都不起作用
+只读的EasyJSON可以在多个goroutine间共享而不需要加锁，例如:
+   var config atomic.Pointer[EasyJSON.EasyJSON]
+   config.Store(doc.Freeze())
+   config.Store(config.Load().With("server.port", 8080))
+*/
+func (easyJSON *EasyJSON) Freeze() *EasyJSON {
+	if easyJSON.frozen {
+		return easyJSON
+	}
+	frozen := easyJSON.Clone()
+	frozen.materialize()  // 懒解析的文档在读取时也会修改自身，不能在goroutine间共享
+	frozen.frozen = true
+	return frozen
+}
+
+/*
+是否只读
+*/
+func (easyJSON *EasyJSON) IsFrozen() bool {
+	return easyJSON.frozen
+}
+
+/*
+返回把path处的值设置为value后的新文档，原文档不变
+path的规则与Set相同，新文档是只读的
+只拷贝从最外层到path的各级节点，其它节点与原文档共享(path copying)
+path无效时返回nil
+*/
+func (easyJSON *EasyJSON) With(path string, value interface{}) *EasyJSON {
+	base := easyJSON.Freeze()
+
+	// 只读的EasyJSON可以直接共享，其它值需要拷贝，避免之后被修改
+	if json, ok := value.(*EasyJSON); ok && json.frozen {
+		value = json.root()
+	} else {
+		value = cloneValue(valueEncoder(value))
+	}
+
+	nameList, err := parsePath(path)
+	if err != nil || len(nameList) == 0 {
+		return nil
+	}
+	root, err := withValue(base.root(), nameList, value)
+	if err != nil {
+		return nil
+	}
+
+	result := &EasyJSON{jsonType: base.jsonType, opts: base.opts, frozen: true}
+	result.setRoot(root)
+	return result
+}
+
+/*
+返回node的拷贝，把nameList处的值设置为value
+只拷贝路径上的节点
+*/
+func withValue(node interface{}, nameList []string, value interface{}) (interface{}, error) {
+	name := nameList[0]
+	last := len(nameList) == 1
+
+	if name[0] == '[' {  // 表明是数组
+		size := len(name)
+		index, _ := strconv.Atoi(name[1 : size - 1])  // 去除前后中括号
+
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, ErrNotAnArray
+		}
+		if index < 0 || index >= len(a) {  // 数组越界
+			return nil, ErrIndexOutOfBounds
+		}
+
+		child := value
+		if !last {
+			var err error
+			if child, err = withValue(a[index], nameList[1:], value); err != nil {
+				return nil, err
+			}
+		}
+		c := make([]interface{}, len(a))
+		copy(c, a)
+		c[index] = child
+		return c, nil
+	}
+
+	// 表明是对象
+	m, ok := node.(*orderedMap)
+	if !ok {
+		return nil, ErrNotAnObject
+	}
+
+	child := value
+	if !last {
+		val, ok := m.get(fieldName(name))
+		if !ok {
+			return nil, ErrFieldNotExists
+		}
+		var err error
+		if child, err = withValue(val, nameList[1:], value); err != nil {
+			return nil, err
+		}
+	}
+	c := m.shallowCopy()
+	c.set(fieldName(name), child)
+	return c, nil
+}
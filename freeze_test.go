@@ -0,0 +1,91 @@
+package EasyJSON
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	doc, _ := Parse(`{"a":{"b":[1,2,{"c":3}]},"d":{"e":1}}`)
+	frozen := doc.Freeze()
+
+	if err := frozen.Set("x", 1); err != ErrImmutable {
+		t.Fatalf("Set: got %v, want ErrImmutable", err)
+	}
+	if err := frozen.Append("a.b", 1); err != ErrImmutable {
+		t.Fatalf("Append: got %v, want ErrImmutable", err)
+	}
+	if err := frozen.Delete("d"); err != ErrImmutable {
+		t.Fatalf("Delete: got %v, want ErrImmutable", err)
+	}
+	view, _ := frozen.GetObject("a")
+	if err := view.Set("z", 1); err != ErrImmutable {
+		t.Fatalf("view Set: got %v, want ErrImmutable", err)
+	}
+
+	next := frozen.With("a.b[2].c", 4)
+	if got := next.String(); got != `{"a":{"b":[1,2,{"c":4}]},"d":{"e":1}}` {
+		t.Fatalf("With: got %s", got)
+	}
+	if got := frozen.String(); got != `{"a":{"b":[1,2,{"c":3}]},"d":{"e":1}}` {
+		t.Fatalf("With modified the original: %s", got)
+	}
+	if next.m.m["d"] != frozen.m.m["d"] {
+		t.Fatal("With copied an untouched subtree")
+	}
+}
+
+func TestFreezeSettersIgnored(t *testing.T) {
+	doc, _ := Parse(`{"b":1,"a":2}`)
+	frozen := doc.Freeze()
+
+	frozen.SetSortKeys(true)
+	frozen.SetEscapeHTML(true)
+	frozen.EnableJournal()
+	frozen.Watch("", func(Change) {})()
+	if frozen.opts != doc.opts || frozen.journal != nil || len(frozen.watchers) > 0 {
+		t.Fatal("frozen document was modified")
+	}
+	if got := frozen.String(); got != `{"b":1,"a":2}` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+/*
+多个goroutine同时读取同一个只读的EasyJSON，用go test -race运行
+*/
+func TestFreezeConcurrentRead(t *testing.T) {
+	for _, text := range []string{
+		`{"a":{"b":[1,2,{"c":3}]},"d":"x"}`,
+		`[1,{"a":[2,3]},[4,5],"x"]`,
+	} {
+		doc, _ := Parse(text)
+		frozen := doc.Freeze()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					frozen.Walk(func(path string, value interface{}, kind Kind) WalkAction {
+						return WALK_DELETE
+					})
+					if got := frozen.String(); got != text {
+						t.Errorf("got %s, want %s", got, text)
+						return
+					}
+					frozen.Range(func(key interface{}, value interface{}) {})
+					frozen.Exists("a")
+					frozen.GetArray("[2]")
+					frozen.Clone().Set("[0]", 0)
+					frozen.With("[0]", j)
+					frozen.SetSortKeys(true)
+					frozen.Watch("a", func(Change) {})
+					frozen.EnableJournal()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
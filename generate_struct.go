@@ -0,0 +1,280 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+由JSON文档生成Go的类型定义，结果已经gofmt格式化
+   1. JSON对象生成结构体，字段带json tag，嵌套的JSON对象生成单独的结构体
+   2. JSON数组生成切片，所有元素的类型合并为一个元素类型
+   3. 数字都是整数时为int64，否则为float64
+   4. 出现过null的字段为*T，在部分元素中缺少的字段带omitempty
+   5. 类型不一致或者无法确定时为interface{}
+name: 最外层类型的名字
+解析后的数字都是float64，1.0与1无法区分，都当作整数，需要按原文区分时使用GenerateStructFromJSON
+*/
+func GenerateStruct(name string, doc *EasyJSON) ([]byte, error) {
+	if doc == nil {
+		return nil, ErrInvalidArguments
+	}
+	return generateStruct(name, doc.root())
+}
+
+/*
+由JSON字节生成Go的类型定义，与GenerateStruct相同
+数字按原文判断，有小数点或指数的(例如1.0、1e3)为float64
+data不是合法的JSON时返回ErrInvalidJSONString
+*/
+func GenerateStructFromJSON(name string, data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeAll(dec)
+	if err != nil {
+		return nil, ErrInvalidJSONString
+	}
+	return generateStruct(name, value)
+}
+
+/*
+value: 底层的数据表示，数字可以是json.Number
+*/
+func generateStruct(name string, value interface{}) ([]byte, error) {
+	shape := &typeShape{}
+	shape.add(value)
+
+	g := &structGenerator{names: make(map[string]bool)}
+	g.declare(exportedName(name), shape)
+
+	return format.Source(g.buf.Bytes())
+}
+
+const (
+	shapeBool = 1 << iota
+	shapeInt
+	shapeFloat
+	shapeString
+	shapeObject
+	shapeArray
+	shapeOther
+)
+
+/*
+同一位置上所有值的类型信息
+*/
+type typeShape struct {
+	kinds    int   // 出现过的类型，shapeXXX的组合
+	nullable bool  // 出现过null
+
+	objects int  // 有多少个值是JSON对象
+	fields  []*fieldShape
+	index   map[string]*fieldShape
+
+	elem *typeShape  // JSON数组元素的类型，数组都为空时为nil
+}
+
+type fieldShape struct {
+	name  string
+	count int  // 在多少个JSON对象中出现
+	shape *typeShape
+}
+
+func (shape *typeShape) add(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		shape.nullable = true
+	case bool:
+		shape.kinds |= shapeBool
+	case string:
+		shape.kinds |= shapeString
+	case *orderedMap:
+		shape.kinds |= shapeObject
+		shape.objects++
+		if shape.index == nil {
+			shape.index = make(map[string]*fieldShape)
+		}
+		for _, k := range v.keys {
+			field, ok := shape.index[k]
+			if !ok {
+				field = &fieldShape{name: k, shape: &typeShape{}}
+				shape.index[k] = field
+				shape.fields = append(shape.fields, field)
+			}
+			field.count++
+			field.shape.add(v.m[k])
+		}
+	case []interface{}:
+		shape.kinds |= shapeArray
+		for _, elem := range v {
+			if shape.elem == nil {
+				shape.elem = &typeShape{}
+			}
+			shape.elem.add(elem)
+		}
+	case json.Number:
+		if _, err := v.Int64(); err == nil && !strings.ContainsAny(v.String(), ".eE") {
+			shape.kinds |= shapeInt
+		} else {
+			shape.kinds |= shapeFloat
+		}
+	default:
+		f, ok := toFloat64(value)
+		switch {
+		case !ok:
+			shape.kinds |= shapeOther
+		case f == math.Trunc(f) && math.Abs(f) < 1<<63:
+			shape.kinds |= shapeInt
+		default:
+			shape.kinds |= shapeFloat
+		}
+	}
+}
+
+type structGenerator struct {
+	buf     bytes.Buffer
+	names   map[string]bool  // 已经使用的类型名
+	pending []pendingType    // 还没有生成的嵌套结构体
+}
+
+type pendingType struct {
+	name  string
+	shape *typeShape
+}
+
+/*
+生成名为name的类型，以及它用到的所有嵌套结构体
+*/
+func (g *structGenerator) declare(name string, shape *typeShape) {
+	name = g.uniqueName(name)
+	g.buf.WriteString("type " + name + " ")
+	if shape.kinds == shapeObject {
+		g.writeStruct(name, shape)
+	} else {
+		g.buf.WriteString(g.typeName(name+"Item", shape, false))
+	}
+	g.buf.WriteString("\n\n")
+
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		g.buf.WriteString("type " + next.name + " ")
+		g.writeStruct(next.name, next.shape)
+		g.buf.WriteString("\n\n")
+	}
+}
+
+func (g *structGenerator) writeStruct(name string, shape *typeShape) {
+	g.buf.WriteString("struct {\n")
+
+	used := make(map[string]bool)
+	for _, field := range shape.fields {
+		fieldName := exportedName(field.name)
+		for i := 2; used[fieldName]; i++ {
+			fieldName = exportedName(field.name) + strconv.Itoa(i)
+		}
+		used[fieldName] = true
+
+		// 缺少的结构体字段用指针，omitempty才能生效
+		optional := field.count < shape.objects
+		pointer := field.shape.nullable || (optional && field.shape.kinds == shapeObject)
+
+		tag := field.name
+		if optional {
+			tag += ",omitempty"
+		}
+		g.buf.WriteString(fieldName + " " + g.typeName(name+fieldName, field.shape, pointer))
+		tagText := "json:" + strconv.Quote(tag)
+		if strings.ContainsRune(tagText, '`') {  // 原始字符串中不能有反引号
+			g.buf.WriteString(" " + strconv.Quote(tagText) + "\n")
+		} else {
+			g.buf.WriteString(" `" + tagText + "`\n")
+		}
+	}
+
+	g.buf.WriteString("}")
+}
+
+/*
+返回shape对应的Go类型
+nestedName: 如果是JSON对象，生成的结构体的名字
+pointer: 是否使用指针类型，对切片和interface{}无效
+*/
+func (g *structGenerator) typeName(nestedName string, shape *typeShape, pointer bool) string {
+	var t string
+	switch shape.kinds {
+	case shapeBool:
+		t = "bool"
+	case shapeInt:
+		t = "int64"
+	case shapeInt | shapeFloat, shapeFloat:
+		t = "float64"
+	case shapeString:
+		t = "string"
+	case shapeObject:
+		name := g.uniqueName(nestedName)
+		g.pending = append(g.pending, pendingType{name, shape})
+		t = name
+	case shapeArray:
+		if shape.elem == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.typeName(nestedName, shape.elem, shape.elem.nullable)
+	default:
+		return "interface{}"
+	}
+
+	if pointer {
+		return "*" + t
+	}
+	return t
+}
+
+func (g *structGenerator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// 常见的缩写，按Go的命名习惯全部大写
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+/*
+把JSON字段名转换为导出的Go标识符，例如 "user_id" 转换为 "UserID"
+*/
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+
+	result := sb.String()
+	if result == "" {
+		return "Field"
+	}
+	if first := []rune(result)[0]; !unicode.IsLetter(first) || !unicode.IsUpper(first) {
+		result = "X" + result
+	}
+	return result
+}
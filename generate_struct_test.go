@@ -0,0 +1,89 @@
+package EasyJSON
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerateStruct(t *testing.T) {
+	doc, _ := Parse(`{"id":1,"user_name":"x","tags":["a"],"owner":{"url":"u"},"items":[{"n":1},{"n":2.5,"x":null}]}`)
+	src, err := GenerateStruct("book", doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "type Book struct {\n" +
+		"\tID       int64       `json:\"id\"`\n" +
+		"\tUserName string      `json:\"user_name\"`\n" +
+		"\tTags     []string    `json:\"tags\"`\n" +
+		"\tOwner    BookOwner   `json:\"owner\"`\n" +
+		"\tItems    []BookItems `json:\"items\"`\n" +
+		"}\n\n" +
+		"type BookOwner struct {\n" +
+		"\tURL string `json:\"url\"`\n" +
+		"}\n\n" +
+		"type BookItems struct {\n" +
+		"\tN float64     `json:\"n\"`\n" +
+		"\tX interface{} `json:\"x,omitempty\"`\n" +
+		"}\n"
+	if strings.TrimSpace(string(src)) != strings.TrimSpace(want) {
+		t.Fatalf("got\n%s", src)
+	}
+
+	if _, err := GenerateStruct("T", nil); err != ErrInvalidArguments {
+		t.Fatalf("got %v, want ErrInvalidArguments", err)
+	}
+}
+
+func TestGenerateStructBacktickKey(t *testing.T) {
+	doc, _ := Parse("{\"a`b\":1,\"c\":2}")
+	src, err := GenerateStruct("T", doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+string(src), 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, src)
+	}
+	var tags []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if field, ok := n.(*ast.Field); ok && field.Tag != nil {
+			tag, _ := strconv.Unquote(field.Tag.Value)
+			tags = append(tags, reflect.StructTag(tag).Get("json"))
+		}
+		return true
+	})
+	if len(tags) != 2 || tags[0] != "a`b" || tags[1] != "c" {
+		t.Fatalf("got tags %q", tags)
+	}
+}
+
+func TestGenerateStructFromJSON(t *testing.T) {
+	src, err := GenerateStructFromJSON("Root", []byte(`{"a":1.0,"b":2,"c":1e3,"d":[1,2.0],"e":[3,4]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"A float64   `json:\"a\"`",
+		"B int64     `json:\"b\"`",
+		"C float64   `json:\"c\"`",
+		"D []float64 `json:\"d\"`",
+		"E []int64   `json:\"e\"`",
+	} {
+		if !strings.Contains(string(src), line) {
+			t.Errorf("missing %q in\n%s", line, src)
+		}
+	}
+
+	for _, data := range []string{``, `{"a":`, `{"a":1} {}`} {
+		if _, err := GenerateStructFromJSON("Root", []byte(data)); err != ErrInvalidJSONString {
+			t.Errorf("%q: got %v, want ErrInvalidJSONString", data, err)
+		}
+	}
+}
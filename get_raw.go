@@ -0,0 +1,84 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+var ErrTypeMismatch = errors.New("type mismatch")
+
+/*
+直接在JSON字节中查找path处的值，返回其原始字节(jsonBytes的子切片)
+不解析整个文档，也不为其它部分分配内存，适合只读取一两个字段的场景
+path与Get、Set的path语法相同，""表示整个文档
+只检查经过的部分，跳过的值只确定其边界，不做完整的校验
+path为""(或者只有空白)时返回的是整个文档，会完整地校验
+*/
+func GetRaw(jsonBytes []byte, path string) ([]byte, error) {
+	data := bytes.TrimSpace(jsonBytes)
+	if len(data) == 0 {
+		return nil, ErrInvalidJSONString
+	}
+	nameList, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(nameList) == 0 {  // 整个文档，包括只有空白的path
+		if !json.Valid(data) {
+			return nil, ErrInvalidJSONString
+		}
+		return data, nil
+	}
+	return rawLookup(data, nameList)
+}
+
+/*
+读取path处的字符串，不是字符串时返回ErrTypeMismatch
+*/
+func GetStringFrom(jsonBytes []byte, path string) (string, error) {
+	raw, err := GetRaw(jsonBytes, path)
+	if err != nil {
+		return "", err
+	}
+	if raw[0] != '"' {
+		return "", ErrTypeMismatch
+	}
+	if len(raw) < 2 || raw[len(raw)-1] != '"' {
+		return "", ErrInvalidJSONString
+	}
+
+	// 没有转义字符时直接截取
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", ErrInvalidJSONString
+	}
+	return s, nil
+}
+
+/*
+读取path处的整数，带小数的数字与GetInt64一样截断为整数
+不是数字时返回ErrTypeMismatch
+*/
+func GetInt64From(jsonBytes []byte, path string) (int64, error) {
+	raw, err := GetRaw(jsonBytes, path)
+	if err != nil {
+		return 0, err
+	}
+	if raw[0] != '-' && (raw[0] < '0' || raw[0] > '9') {
+		return 0, ErrTypeMismatch
+	}
+
+	if n, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return int64(f), nil
+}
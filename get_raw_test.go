@@ -0,0 +1,76 @@
+package EasyJSON
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 200个章节，要读取的字段在最后
+var getRawDoc = func() []byte {
+	var sb strings.Builder
+	sb.WriteString(`{"chapters":[`)
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"title":"Chapter %d","pages":[1,2,3],"meta":{"a":"b"}}`, i)
+	}
+	sb.WriteString(`],"book":{"title":"Go é","year":2018.5}}`)
+	return []byte(sb.String())
+}()
+
+func TestGetRaw(t *testing.T) {
+	if s, err := GetStringFrom(getRawDoc, "book.title"); err != nil || s != "Go é" {
+		t.Fatalf("book.title: got %q, %v", s, err)
+	}
+	if s, err := GetStringFrom(getRawDoc, "chapters[150].title"); err != nil || s != "Chapter 150" {
+		t.Fatalf("chapters[150].title: got %q, %v", s, err)
+	}
+	if n, err := GetInt64From(getRawDoc, "book.year"); err != nil || n != 2018 {
+		t.Fatalf("book.year: got %d, %v", n, err)
+	}
+	if _, err := GetInt64From(getRawDoc, "book.title"); err != ErrTypeMismatch {
+		t.Fatalf("book.title as int64: got %v, want ErrTypeMismatch", err)
+	}
+	if raw, err := GetRaw(getRawDoc, "chapters[0].meta"); err != nil || string(raw) != `{"a":"b"}` {
+		t.Fatalf("chapters[0].meta: got %s, %v", raw, err)
+	}
+	if _, err := GetRaw(getRawDoc, "nope"); err != ErrFieldNotExists {
+		t.Fatalf("nope: got %v, want ErrFieldNotExists", err)
+	}
+}
+
+func TestGetRawInvalid(t *testing.T) {
+	for _, data := range []string{`"`, `"abc`, `{"a":1`, `[1,]`, `tru`} {
+		for _, path := range []string{"", " ", "\t\n"} {
+			if _, err := GetRaw([]byte(data), path); err != ErrInvalidJSONString {
+				t.Errorf("GetRaw(%s, %q): got %v, want ErrInvalidJSONString", data, path, err)
+			}
+		}
+		if _, err := GetStringFrom([]byte(data), ""); err != ErrInvalidJSONString {
+			t.Errorf("GetStringFrom(%s): got %v, want ErrInvalidJSONString", data, err)
+		}
+	}
+	if _, err := GetStringFrom([]byte(`{"a":"}`), "a"); err != ErrInvalidJSONString {
+		t.Errorf("unterminated string: got %v, want ErrInvalidJSONString", err)
+	}
+	if s, err := GetStringFrom([]byte(` "x" `), ""); err != nil || s != "x" {
+		t.Errorf("top-level string: got %q, %v", s, err)
+	}
+}
+
+func BenchmarkGetStringFrom(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		GetStringFrom(getRawDoc, "book.title")
+	}
+}
+
+func BenchmarkParseGetString(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		doc, _ := Parse(string(getRawDoc))
+		doc.GetString("book.title")
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/373518155/EasyJSONGo
+
+go 1.23
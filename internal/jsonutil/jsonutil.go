@@ -0,0 +1,85 @@
+/*
+EasyJSON和schema等子包共用的工具函数，不对外公开
+*/
+package jsonutil
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+把各种数字类型(包括以数字为底层类型的自定义类型)转换为float64
+如果value不是数字，第2个返回值为false
+*/
+func ToFloat64(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+/*
+生成Get、Set等使用的路径
+   JoinPath("a.b", "c")   返回 "a.b.c"
+   JoinPath("a.b", "c.d") 返回 "a.b[\"c.d\"]"
+   IndexPath("a.b", 2)    返回 "a.b[2]"
+字段名中有 . [ ] " 等字符，或者为空、为"*"、前后有空白时，用中括号括起的JSON字符串表示
+*/
+func JoinPath(parent string, name string) string {
+	if name == "" || name == "*" || strings.ContainsAny(name, ".[]\"") || strings.TrimSpace(name) != name {
+		return parent + "[" + Quote(name) + "]"
+	}
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func IndexPath(parent string, index int) string {
+	return parent + "[" + strconv.Itoa(index) + "]"
+}
+
+/*
+把s表示为JSON字符串，只转义必须转义的字符
+*/
+func Quote(s string) string {
+	const hex = "0123456789abcdef"
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				b.WriteString(`\u00`)
+				b.WriteByte(hex[c>>4])
+				b.WriteByte(hex[c&0xF])
+				continue
+			}
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,167 @@
+//go:build go1.23
+
+package EasyJSON
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+/*
+遍历JSON对象的字段，按字段的插入(或解析)顺序
+   for name, value := range easyJSON.Entries() { ... }
+value中的JSON对象和JSON数组以*EasyJSON表示(与原文档共享数据)
+如果EasyJSON不是JSON对象，不产生任何元素
+*/
+func (easyJSON *EasyJSON) Entries() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		easyJSON.materialize()
+		if easyJSON.jsonType != JSON_TYPE_OBJECT {
+			return
+		}
+		for _, k := range easyJSON.m.keys {
+			value, ok := easyJSON.m.get(k)
+			if !ok {  // 遍历过程中被删除了
+				continue
+			}
+			if !yield(k, wrapValue(value, easyJSON)) {
+				return
+			}
+		}
+	}
+}
+
+/*
+遍历JSON数组的元素
+   for index, value := range easyJSON.Elements() { ... }
+value中的JSON对象和JSON数组以*EasyJSON表示(与原文档共享数据)
+如果EasyJSON不是JSON数组，不产生任何元素
+*/
+func (easyJSON *EasyJSON) Elements() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		easyJSON.materialize()
+		if easyJSON.jsonType != JSON_TYPE_ARRAY {
+			return
+		}
+		for i, value := range easyJSON.a {
+			if !yield(i, wrapValue(value, easyJSON)) {
+				return
+			}
+		}
+	}
+}
+
+/*
+深度优先遍历整个文档的叶子节点
+   for path, value := range easyJSON.Leaves() { ... }
+叶子节点是除JSON对象、JSON数组以外的值，
+空的JSON对象和JSON数组没有叶子节点，所以也作为叶子节点，以*EasyJSON表示
+path与Get、Set的path语法相同
+*/
+func (easyJSON *EasyJSON) Leaves() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		easyJSON.materialize()
+		// 只读取，不经过Walk，开启了修改日志或Watch时也不需要拷贝文档
+		yieldChildren(easyJSON, "", easyJSON.root(), yield)
+	}
+}
+
+/*
+依次产生JSON对象或JSON数组value中的叶子节点，yield返回false时返回false
+doc: 所属的文档，叶子节点中的*EasyJSON与它的只读状态和输出选项相同
+*/
+func yieldChildren(doc *EasyJSON, path string, value interface{}, yield func(string, interface{}) bool) bool {
+	switch v := value.(type) {
+	case *orderedMap:
+		keys := make([]string, len(v.keys))
+		copy(keys, v.keys)
+		for _, k := range keys {
+			elem, ok := v.get(k)
+			if !ok {  // 遍历过程中被删除了
+				continue
+			}
+			if !yieldLeaf(doc, joinPath(path, k), elem, yield) {
+				return false
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if !yieldLeaf(doc, indexPath(path, i), elem, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func yieldLeaf(doc *EasyJSON, path string, value interface{}, yield func(string, interface{}) bool) bool {
+	switch v := value.(type) {
+	case *orderedMap:
+		if v.len() > 0 {
+			return yieldChildren(doc, path, v, yield)
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return yieldChildren(doc, path, v, yield)
+		}
+	}
+	return yield(path, wrapValue(value, doc))
+}
+
+/*
+逐条读取NDJSON记录
+   for record, err := range reader.Records() { ... }
+某一行有错误时产生(nil, *LineError)，然后继续读取下一行
+读取r出错时产生(nil, err)后结束，读到末尾时直接结束
+*/
+func (reader *LineReader) Records() iter.Seq2[*EasyJSON, error] {
+	return func(yield func(*EasyJSON, error) bool) {
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(record, err) {
+				return
+			}
+
+			var lineErr *LineError
+			if err != nil && !errors.As(err, &lineErr) {
+				return
+			}
+		}
+	}
+}
+
+/*
+依次解析文档
+   for doc, err := range decoder.Documents() { ... }
+解析出错时产生(nil, err)后结束，读到末尾时直接结束
+*/
+func (decoder *Decoder) Documents() iter.Seq2[*EasyJSON, error] {
+	return func(yield func(*EasyJSON, error) bool) {
+		for {
+			doc, err := decoder.Next()
+			if err == io.EOF || !yield(doc, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+/*
+依次读取数组的元素
+   for item, err := range stream.Documents() { ... }
+出错时产生(nil, err)后结束，读完所有元素时直接结束
+*/
+func (stream *ArrayStream) Documents() iter.Seq2[*EasyJSON, error] {
+	return func(yield func(*EasyJSON, error) bool) {
+		for {
+			doc, err := stream.Next()
+			if err == io.EOF || !yield(doc, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build go1.23
+
+package EasyJSON
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestLeaves(t *testing.T) {
+	doc, _ := Parse(`{"a":{"b":[1,{}],"c.d":null},"e":[],"f":"x"}`)
+
+	var paths []string
+	for path, value := range doc.Leaves() {
+		paths = append(paths, path)
+		if path == "a.b[1]" || path == "e" {
+			if _, ok := value.(*EasyJSON); !ok {
+				t.Errorf("%s: empty container is %T, want *EasyJSON", path, value)
+			}
+		}
+		if path == "f" {
+			break
+		}
+	}
+	want := []string{"a.b[0]", "a.b[1]", `a["c.d"]`, "e", "f"}
+	if len(paths) != len(want) {
+		t.Fatalf("got %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("got %v, want %v", paths, want)
+		}
+	}
+}
+
+/*
+开启修改日志时，Leaves也不应该拷贝整个文档
+*/
+func TestLeavesNoSnapshot(t *testing.T) {
+	doc := Array()
+	for i := 0; i < 1000; i++ {
+		doc.Append("", strconv.Itoa(i))
+	}
+	count := func() float64 {
+		return testing.AllocsPerRun(10, func() {
+			for range doc.Leaves() {
+			}
+		})
+	}
+
+	plain := count()
+	doc.EnableJournal()
+	if observed := count(); observed > plain+10 {
+		t.Fatalf("Leaves with a journal: %v allocs, without: %v", observed, plain)
+	}
+}
+
+func TestEntriesElements(t *testing.T) {
+	doc, _ := Parse(`{"b":1,"a":[true,null]}`)
+
+	var keys []string
+	for k := range doc.Entries() {
+		keys = append(keys, k)
+	}
+	if len(keys) != 2 || keys[0] != "b" || keys[1] != "a" {
+		t.Fatalf("Entries: got %v", keys)
+	}
+
+	list, _ := doc.GetArray("a")
+	n := 0
+	for i, value := range list.Elements() {
+		if i != n || (i == 0 && value != true) || (i == 1 && value != nil) {
+			t.Fatalf("Elements: got %d %v", i, value)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("Elements: got %d elements", n)
+	}
+
+	// 类型不符时不产生任何元素
+	for range list.Entries() {
+		t.Fatal("Entries of an array produced an element")
+	}
+	for range doc.Elements() {
+		t.Fatal("Elements of an object produced an element")
+	}
+}
@@ -0,0 +1,353 @@
+package EasyJSON
+
+import (
+	"errors"
+)
+
+var (
+	ErrNothingToUndo     = errors.New("nothing to undo")
+	ErrNothingToRedo     = errors.New("nothing to redo")
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+)
+
+/*
+修改日志，记录通过EasyJSON进行的每一次修改
+*/
+type journal struct {
+	entries []journalEntry
+	pos     int  // 已经生效的记录数，entries[pos:]是可以Redo的记录
+	nextID  int
+}
+
+type journalEntry struct {
+	id       int
+	change   Change    // OldValue、NewValue为底层的数据表示
+	tokens   []string  // change.Path对应的JSON Pointer
+	keyIndex int       // 删除JSON对象的字段时，字段原来的位置，其它情况为-1
+}
+
+/*
+开启修改日志
+之后通过Set、Append、Delete、ApplyPatch、MergePatch、Merge、Walk进行的修改都会被记录，
+可以用Undo、Redo撤销和重做
+ApplyPatch、MergePatch、Merge、Walk、Transaction作为对最外层的一次修改记录
+通过GetObject、GetArray返回的视图进行的修改不会被记录，开启日志后不应该再这样修改
+已经开启时，清空原来的日志
+只读的EasyJSON(见Freeze)不能修改，不开启日志
+*/
+func (easyJSON *EasyJSON) EnableJournal() {
+	if easyJSON.frozen {
+		return
+	}
+	easyJSON.journal = &journal{}
+}
+
+/*
+关闭修改日志并丢弃已有的记录
+*/
+func (easyJSON *EasyJSON) DisableJournal() {
+	if easyJSON.journal == nil {
+		return
+	}
+	easyJSON.journal = nil
+}
+
+/*
+返回已经生效的修改记录，按修改的顺序排列
+*/
+func (easyJSON *EasyJSON) Journal() []Change {
+	if easyJSON.journal == nil {
+		return nil
+	}
+
+	changes := make([]Change, 0, easyJSON.journal.pos)
+	for _, entry := range easyJSON.journal.entries[:easyJSON.journal.pos] {
+		change := entry.change
+		change.OldValue = exportValue(change.OldValue)
+		change.NewValue = exportValue(change.NewValue)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+/*
+把已经生效的修改记录导出为RFC 6902 (JSON Patch)
+在开启日志时的文档上执行ApplyPatch，得到当前的文档
+*/
+func (easyJSON *EasyJSON) JournalPatch() *EasyJSON {
+	patch := Array()
+	if easyJSON.journal == nil {
+		return patch
+	}
+
+	for _, entry := range easyJSON.journal.entries[:easyJSON.journal.pos] {
+		pointer := ""
+		for _, token := range entry.tokens {
+			pointer = formatPointer(pointer, token)
+		}
+
+		var op *EasyJSON
+		switch entry.change.Kind {
+		case CHANGE_ADDED:
+			op = Object("op", "add", "path", pointer, "value", cloneValue(entry.change.NewValue))
+		case CHANGE_REMOVED:
+			op = Object("op", "remove", "path", pointer)
+		default:
+			op = Object("op", "replace", "path", pointer, "value", cloneValue(entry.change.NewValue))
+		}
+		patch.a = append(patch.a, op.m)
+	}
+	return patch
+}
+
+func (easyJSON *EasyJSON) CanUndo() bool {
+	return easyJSON.journal != nil && easyJSON.journal.pos > 0
+}
+
+func (easyJSON *EasyJSON) CanRedo() bool {
+	return easyJSON.journal != nil && easyJSON.journal.pos < len(easyJSON.journal.entries)
+}
+
+/*
+撤销最近的一次修改
+*/
+func (easyJSON *EasyJSON) Undo() error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	if !easyJSON.CanUndo() {
+		return ErrNothingToUndo
+	}
+
+	j := easyJSON.journal
+	root, err := j.entries[j.pos-1].undo(easyJSON.root())
+	if err != nil {
+		return err
+	}
+	easyJSON.setRoot(root)
+	j.pos--
+	easyJSON.notifyEntry(&j.entries[j.pos], true)
+	return nil
+}
+
+/*
+重做最近一次撤销的修改
+撤销之后又进行了新的修改时，不能再重做
+*/
+func (easyJSON *EasyJSON) Redo() error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	if !easyJSON.CanRedo() {
+		return ErrNothingToRedo
+	}
+
+	j := easyJSON.journal
+	root, err := j.entries[j.pos].redo(easyJSON.root())
+	if err != nil {
+		return err
+	}
+	easyJSON.setRoot(root)
+	j.pos++
+	easyJSON.notifyEntry(&j.entries[j.pos-1], false)
+	return nil
+}
+
+/*
+返回当前状态的标识，传给RollbackTo可以回到当前状态
+没有开启修改日志时返回0
+*/
+func (easyJSON *EasyJSON) Checkpoint() int {
+	j := easyJSON.journal
+	if j == nil || j.pos == 0 {
+		return 0
+	}
+	return j.entries[j.pos-1].id
+}
+
+/*
+撤销checkpoint之后的所有修改
+checkpoint之后的修改已经被撤销，又进行了新的修改时，返回ErrInvalidCheckpoint
+*/
+func (easyJSON *EasyJSON) RollbackTo(checkpoint int) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	j := easyJSON.journal
+	if j == nil {
+		return ErrInvalidCheckpoint
+	}
+
+	target := -1
+	if checkpoint == 0 {
+		target = 0
+	}
+	for i, entry := range j.entries[:j.pos] {
+		if entry.id == checkpoint {
+			target = i + 1
+			break
+		}
+	}
+	if target < 0 {
+		return ErrInvalidCheckpoint
+	}
+
+	for j.pos > target {
+		if err := easyJSON.Undo(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (entry *journalEntry) undo(root interface{}) (interface{}, error) {
+	switch entry.change.Kind {
+	case CHANGE_ADDED:
+		root, _, err := pointerRemove(root, entry.tokens)
+		return root, err
+	case CHANGE_REMOVED:
+		value := cloneValue(entry.change.OldValue)
+		if entry.keyIndex < 0 {
+			return pointerAdd(root, entry.tokens, value)
+		}
+		// 字段放回原来的位置
+		nameList, err := pointerNames(root, entry.tokens, false)
+		if err != nil {
+			return nil, err
+		}
+		return updateValue(root, nameList, func(parent interface{}, name string) (interface{}, error) {
+			m, ok := parent.(*orderedMap)
+			if !ok {
+				return nil, ErrNotAnObject
+			}
+			m.insert(entry.keyIndex, fieldName(name), value)
+			return m, nil
+		})
+	}
+	return pointerReplace(root, entry.tokens, cloneValue(entry.change.OldValue))
+}
+
+func (entry *journalEntry) redo(root interface{}) (interface{}, error) {
+	switch entry.change.Kind {
+	case CHANGE_ADDED:
+		return pointerAdd(root, entry.tokens, cloneValue(entry.change.NewValue))
+	case CHANGE_REMOVED:
+		root, _, err := pointerRemove(root, entry.tokens)
+		return root, err
+	}
+	return pointerReplace(root, entry.tokens, cloneValue(entry.change.NewValue))
+}
+
+/*
+是否需要记录修改: 开启了修改日志、有Watch或者在Transaction中
+*/
+func (easyJSON *EasyJSON) observed() bool {
+	return easyJSON.journal != nil || len(easyJSON.watchers) > 0 || easyJSON.pending != nil
+}
+
+/*
+追加一条修改记录，丢弃可以Redo的记录
+change中的值为底层的数据表示，会被拷贝
+删除时应该在删除前调用，以便记录字段原来的位置
+*/
+func (easyJSON *EasyJSON) record(change Change) {
+	j := easyJSON.journal
+	if j == nil {
+		return
+	}
+
+	tokens := pathTokens(change.Path)
+	keyIndex := -1
+	if change.Kind == CHANGE_REMOVED {
+		keyIndex = fieldIndex(easyJSON.root(), tokens)
+	}
+
+	change.OldValue = cloneValue(change.OldValue)
+	change.NewValue = cloneValue(change.NewValue)
+
+	j.nextID++
+	j.entries = append(j.entries[:j.pos], journalEntry{
+		id:       j.nextID,
+		change:   change,
+		tokens:   tokens,
+		keyIndex: keyIndex,
+	})
+	j.pos++
+}
+
+/*
+记录对最外层的修改
+修改日志中作为一次修改记录，通知Watch时逐项比较，只通知实际变化的路径
+old: 修改前的最外层，不能与修改后的文档共享数据
+*/
+func (easyJSON *EasyJSON) recordRoot(old interface{}) {
+	if !easyJSON.observed() || valueEqual(old, easyJSON.root()) {
+		return
+	}
+
+	kind := CHANGE_MODIFIED
+	if kindOf(old) != kindOf(easyJSON.root()) {
+		kind = CHANGE_TYPE_CHANGED
+	}
+	easyJSON.record(Change{Path: "", Kind: kind, OldValue: old, NewValue: easyJSON.root()})
+
+	if len(easyJSON.watchers) > 0 || easyJSON.pending != nil {
+		d := &differ{}
+		d.diffValue("", old, easyJSON.root())
+		easyJSON.notify(d.changes...)
+	}
+}
+
+/*
+需要记录修改时返回最外层的拷贝，否则返回nil
+*/
+func (easyJSON *EasyJSON) snapshot() interface{} {
+	if !easyJSON.observed() {
+		return nil
+	}
+	return cloneValue(easyJSON.root())
+}
+
+/*
+把Get、Set的path转换为JSON Pointer的各级引用
+*/
+func pathTokens(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	nameList, _ := parsePath(path)  // 已经修改成功的路径
+	tokens := make([]string, len(nameList))
+	for i, name := range nameList {
+		if name[0] == '[' {
+			name = name[1 : len(name)-1]
+		} else {
+			name = fieldName(name)
+		}
+		tokens[i] = name
+	}
+	return tokens
+}
+
+/*
+tokens指向JSON对象的字段时，返回字段在JSON对象中的位置，否则返回-1
+*/
+func fieldIndex(root interface{}, tokens []string) int {
+	if len(tokens) == 0 {
+		return -1
+	}
+	parent, err := pointerGet(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return -1
+	}
+	m, ok := parent.(*orderedMap)
+	if !ok {
+		return -1
+	}
+	for i, k := range m.keys {
+		if k == tokens[len(tokens)-1] {
+			return i
+		}
+	}
+	return -1
+}
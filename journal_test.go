@@ -0,0 +1,152 @@
+package EasyJSON
+
+import "testing"
+
+func TestUndoRedo(t *testing.T) {
+	doc, _ := Parse(`{"a":1,"b":{"c":[1,2]},"d":"x"}`)
+	doc.EnableJournal()
+
+	steps := []struct {
+		change func() error
+		want   string
+	}{
+		{func() error { return doc.Set("a", 2) }, `{"a":2,"b":{"c":[1,2]},"d":"x"}`},
+		{func() error { return doc.Append("b.c", 3) }, `{"a":2,"b":{"c":[1,2,3]},"d":"x"}`},
+		{func() error { return doc.Delete("a") }, `{"b":{"c":[1,2,3]},"d":"x"}`},
+		{func() error { return doc.Set("b.e", Object("f", 1)) }, `{"b":{"c":[1,2,3],"e":{"f":1}},"d":"x"}`},
+		{func() error { return doc.Set("d", Array(1)) }, `{"b":{"c":[1,2,3],"e":{"f":1}},"d":[1]}`},
+	}
+	history := []string{doc.String()}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatal(err)
+		}
+		if got := doc.String(); got != step.want {
+			t.Fatalf("got %s, want %s", got, step.want)
+		}
+		history = append(history, step.want)
+	}
+	if len(doc.Journal()) != len(steps) {
+		t.Fatalf("got %d journal entries, want %d", len(doc.Journal()), len(steps))
+	}
+
+	// 删除的字段放回原来的位置
+	for i := len(history) - 2; i >= 0; i-- {
+		if err := doc.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if got := doc.String(); got != history[i] {
+			t.Fatalf("Undo: got %s, want %s", got, history[i])
+		}
+	}
+	if doc.CanUndo() || doc.Undo() != ErrNothingToUndo {
+		t.Fatal("expected ErrNothingToUndo")
+	}
+
+	for i := 1; i < len(history); i++ {
+		if err := doc.Redo(); err != nil {
+			t.Fatal(err)
+		}
+		if got := doc.String(); got != history[i] {
+			t.Fatalf("Redo: got %s, want %s", got, history[i])
+		}
+	}
+	if doc.CanRedo() || doc.Redo() != ErrNothingToRedo {
+		t.Fatal("expected ErrNothingToRedo")
+	}
+
+	// 撤销后的新修改丢弃可以Redo的记录
+	doc.Undo()
+	doc.Set("z", true)
+	if doc.CanRedo() {
+		t.Fatal("redo kept after a new change")
+	}
+}
+
+func TestJournalDisabled(t *testing.T) {
+	doc, _ := Parse(`{"a":1}`)
+	doc.Set("a", 2)
+	if doc.CanUndo() || doc.Undo() != ErrNothingToUndo || doc.Journal() != nil {
+		t.Fatal("changes recorded without a journal")
+	}
+	if got := doc.JournalPatch().String(); got != `[]` {
+		t.Fatalf("JournalPatch: got %s", got)
+	}
+
+	doc.EnableJournal()
+	doc.Set("a", 3)
+	doc.DisableJournal()
+	if doc.CanUndo() || doc.Journal() != nil {
+		t.Fatal("journal kept after DisableJournal")
+	}
+
+	frozen := doc.Freeze()
+	frozen.EnableJournal()
+	if frozen.Undo() != ErrImmutable || frozen.Redo() != ErrImmutable || frozen.RollbackTo(0) != ErrImmutable {
+		t.Fatal("expected ErrImmutable")
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	doc, _ := Parse(`{"a":1}`)
+	if doc.Checkpoint() != 0 || doc.RollbackTo(0) != ErrInvalidCheckpoint {
+		t.Fatal("expected ErrInvalidCheckpoint without a journal")
+	}
+
+	doc.EnableJournal()
+	start := doc.Checkpoint()
+	doc.Set("b", 2)
+	middle := doc.Checkpoint()
+	doc.Set("c", 3)
+	doc.Delete("a")
+
+	if err := doc.RollbackTo(middle); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":1,"b":2}` {
+		t.Fatalf("got %s", got)
+	}
+
+	// middle之后的修改已经撤销，又进行了新的修改
+	doc.Undo()
+	doc.Set("d", 4)
+	if err := doc.RollbackTo(middle); err != ErrInvalidCheckpoint {
+		t.Fatalf("got %v, want ErrInvalidCheckpoint", err)
+	}
+	if err := doc.RollbackTo(12345); err != ErrInvalidCheckpoint {
+		t.Fatalf("got %v, want ErrInvalidCheckpoint", err)
+	}
+
+	if err := doc.RollbackTo(start); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":1}` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestJournalPatch(t *testing.T) {
+	doc, _ := Parse(`{"a":{"x/y":1,"b":[1,2]},"c":"s"}`)
+	start := doc.Clone()
+	doc.EnableJournal()
+
+	doc.Set(`a["x/y"]`, 5)
+	doc.Append("a.b", Object("k", "~"))
+	doc.Delete("c")
+	doc.Set("n", nil)
+	doc.MergePatch(Object("a", Object("b", nil)))
+
+	patch := doc.JournalPatch()
+	if err := start.ApplyPatch(patch); err != nil {
+		t.Fatalf("%v: %s", err, patch)
+	}
+	if !start.Equal(doc) {
+		t.Fatalf("got %s, want %s", start, doc)
+	}
+
+	ops, _ := patch.GetArray("")
+	first, _ := ops.GetObject("[0]")
+	if got := first.String(); got != `{"op":"replace","path":"/a/x~1y","value":5}` {
+		t.Fatalf("got %s", got)
+	}
+}
@@ -0,0 +1,59 @@
+package EasyJSON
+
+import (
+	"reflect"
+)
+
+/*
+JSON值的类型
+*/
+type Kind int
+
+const (
+	KIND_INVALID = Kind(iota)  // 无法表示为JSON的值
+	KIND_NULL
+	KIND_BOOLEAN
+	KIND_NUMBER
+	KIND_STRING
+	KIND_OBJECT
+	KIND_ARRAY
+)
+
+var kindNames = []string{"invalid", "null", "boolean", "number", "string", "object", "array"}
+
+func (kind Kind) String() string {
+	if kind < 0 || int(kind) >= len(kindNames) {
+		return kindNames[KIND_INVALID]
+	}
+	return kindNames[kind]
+}
+
+/*
+获取底层数据表示的JSON类型
+*/
+func kindOf(value interface{}) Kind {
+	switch value.(type) {
+	case nil:
+		return KIND_NULL
+	case bool:
+		return KIND_BOOLEAN
+	case string:
+		return KIND_STRING
+	case *orderedMap:
+		return KIND_OBJECT
+	case []interface{}:
+		return KIND_ARRAY
+	}
+	if isNumber(value) {
+		return KIND_NUMBER
+	}
+
+	// 以string、bool为底层类型的自定义类型
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.String:
+		return KIND_STRING
+	case reflect.Bool:
+		return KIND_BOOLEAN
+	}
+	return KIND_INVALID
+}
@@ -0,0 +1,175 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+/*
+懒解析的文档保存原始的JSON字节，以及最外层各个字段(或元素)在其中的位置
+*/
+type lazyDoc struct {
+	raw    []byte
+	keys   []string           // JSON对象的字段，按出现的顺序
+	fields map[string][]byte  // JSON对象各字段值的原始字节
+	elems  [][]byte           // JSON数组各元素的原始字节
+
+	parent *EasyJSON     // 由GetObject、GetArray得到时，所属的文档
+	path   string        // 在parent中的路径
+	views  map[string]*EasyJSON  // 由GetObject、GetArray得到的懒解析的视图，每个路径只有一个
+}
+
+/*
+懒解析: 只检查JSON格式并记录最外层各个字段(或元素)的位置，不解析其中的内容
+Get、GetObject等读取操作只解析用到的部分，例如读取"chapters[1].title"时，
+只在原始字节中跳过chapters[0]，只解析title的值
+第一次修改(Set、Append、Delete等)或者需要整个文档时(String、Walk、Clone等)才完整地解析
+data在之后不能再被修改
+*/
+func ParseLazy(data []byte) (*EasyJSON, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') || !json.Valid(data) {
+		return nil, ErrInvalidJSONString
+	}
+	return newLazy(data), nil
+}
+
+/*
+data必须是合法的JSON对象或JSON数组
+*/
+func newLazy(data []byte) *EasyJSON {
+	lazy := &lazyDoc{raw: data}
+	easyJSON := &EasyJSON{lazy: lazy}
+
+	if data[0] == '{' {
+		easyJSON.jsonType = JSON_TYPE_OBJECT
+		lazy.fields = make(map[string][]byte)
+		rawObjectEach(data, func(key string, value []byte) bool {
+			if _, ok := lazy.fields[key]; !ok {
+				lazy.keys = append(lazy.keys, key)
+			}
+			lazy.fields[key] = value
+			return true
+		})
+	} else {
+		easyJSON.jsonType = JSON_TYPE_ARRAY
+		rawArrayEach(data, func(_ int, value []byte) bool {
+			lazy.elems = append(lazy.elems, value)
+			return true
+		})
+	}
+	return easyJSON
+}
+
+/*
+是否是还没有完整解析的懒解析文档
+*/
+func (easyJSON *EasyJSON) IsLazy() bool {
+	return easyJSON.lazy != nil
+}
+
+/*
+返回path处的值的原始字节
+*/
+func (easyJSON *EasyJSON) lazyLookup(path string) ([]byte, error) {
+	lazy := easyJSON.lazy
+	nameList, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(nameList) == 0 {
+		return lazy.raw, nil
+	}
+
+	var data []byte
+	name := nameList[0]
+	if name[0] == '[' {  // 表明是数组
+		if easyJSON.jsonType != JSON_TYPE_ARRAY {
+			return nil, ErrNotAnArray
+		}
+		index, _ := strconv.Atoi(name[1 : len(name)-1])  // 去除前后中括号
+		if index < 0 || index >= len(lazy.elems) {
+			return nil, ErrIndexOutOfBounds
+		}
+		data = lazy.elems[index]
+	} else { // 表明是对象
+		if easyJSON.jsonType != JSON_TYPE_OBJECT {
+			return nil, ErrNotAnObject
+		}
+		value, ok := lazy.fields[fieldName(name)]
+		if !ok {
+			return nil, ErrFieldNotExists
+		}
+		data = value
+	}
+
+	return rawLookup(data, nameList[1:])
+}
+
+/*
+懒解析时的GetObject、GetArray
+返回的视图也是懒解析的，修改它时，原文档与它一起完整解析，然后二者共享数据
+同一个路径多次调用返回同一个视图，反复读取时视图不会越来越多
+*/
+func (easyJSON *EasyJSON) lazyView(path string, jsonType int) (*EasyJSON, error) {
+	data, err := easyJSON.lazyLookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if jsonType == JSON_TYPE_OBJECT && data[0] != '{' {
+		return nil, ErrNotAnObject
+	}
+	if jsonType == JSON_TYPE_ARRAY && data[0] != '[' {
+		return nil, ErrNotAnArray
+	}
+
+	nameList, _ := parsePath(path)  // lazyLookup已经检查过
+	path = formatPath(nameList)
+	if view, ok := easyJSON.lazy.views[path]; ok {
+		return view, nil
+	}
+
+	view := newLazy(data)
+	view.opts = easyJSON.opts
+	view.lazy.parent = easyJSON
+	view.lazy.path = path
+	if easyJSON.lazy.views == nil {
+		easyJSON.lazy.views = make(map[string]*EasyJSON)
+	}
+	easyJSON.lazy.views[path] = view
+	return view, nil
+}
+
+/*
+完整解析懒解析的文档
+视图由所属的文档完整解析，以便与之共享数据
+*/
+func (easyJSON *EasyJSON) materialize() {
+	lazy := easyJSON.lazy
+	if lazy == nil {
+		return
+	}
+	if lazy.parent != nil {
+		lazy.parent.materialize()
+		return
+	}
+
+	root, _ := decodeBytes(lazy.raw)  // ParseLazy已经检查过格式
+	easyJSON.lazy = nil
+	easyJSON.setRoot(root)
+	easyJSON.resolveViews(lazy.views)
+}
+
+/*
+文档完整解析后，让视图指向文档中对应的节点
+*/
+func (easyJSON *EasyJSON) resolveViews(views map[string]*EasyJSON) {
+	for _, view := range views {
+		value, _ := easyJSON.get(view.lazy.path)
+		children := view.lazy.views
+		view.lazy = nil
+		view.setRoot(value)
+		view.resolveViews(children)
+	}
+}
@@ -0,0 +1,39 @@
+package EasyJSON
+
+import "testing"
+
+func TestLazyViewCached(t *testing.T) {
+	doc, err := ParseLazy([]byte(`{"a":{"b":[1,2]},"c":[{"d":1}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := doc.GetObject("a")
+	for i := 0; i < 100; i++ {
+		view, err := doc.GetObject(` a `)
+		if err != nil || view != first {
+			t.Fatalf("got a new view %p, %v", view, err)
+		}
+		doc.GetArray("a.b")
+		doc.GetArray(`["a"]["b"]`)
+		doc.GetObject("c[0]")
+	}
+	if len(doc.lazy.views) != 3 {
+		t.Fatalf("expected 3 views, got %d", len(doc.lazy.views))
+	}
+
+	// 修改后视图与文档共享数据
+	list, _ := doc.GetArray("a.b")
+	if err := list.Set("[1]", 3); err != nil {
+		t.Fatal(err)
+	}
+	if doc.IsLazy() || first.IsLazy() {
+		t.Fatal("document not materialized")
+	}
+	if got := doc.String(); got != `{"a":{"b":[1,3]},"c":[{"d":1}]}` {
+		t.Fatalf("got %s", got)
+	}
+	if got := first.String(); got != `{"b":[1,3]}` {
+		t.Fatalf("got %s", got)
+	}
+}
@@ -0,0 +1,213 @@
+package EasyJSON
+
+import (
+	"errors"
+)
+
+var ErrMergeConflict = errors.New("merge conflict")
+
+/*
+按RFC 7386 (JSON Merge Patch)修改EasyJSON
+   1. patch中的字段覆盖EasyJSON中的同名字段，JSON对象逐层合并
+   2. patch中值为null的字段表示删除该字段
+   3. JSON数组和其它值整体替换，不做合并
+如果patch是JSON数组，EasyJSON整体替换为patch
+*/
+func (easyJSON *EasyJSON) MergePatch(patch *EasyJSON) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	if patch == nil {
+		return ErrInvalidArguments
+	}
+
+	old := easyJSON.snapshot()
+	easyJSON.setRoot(mergePatchValue(easyJSON.root(), patch.root()))
+	easyJSON.recordRoot(old)
+	return nil
+}
+
+func mergePatchValue(target, patch interface{}) interface{} {
+	patchMap, ok := patch.(*orderedMap)
+	if !ok {
+		return cloneValue(patch)
+	}
+
+	targetMap, ok := target.(*orderedMap)
+	if !ok {
+		targetMap = newOrderedMap()
+	}
+	for _, k := range patchMap.keys {
+		value := patchMap.m[k]
+		if value == nil {
+			targetMap.delete(k)
+			continue
+		}
+
+		old, _ := targetMap.get(k)
+		targetMap.set(k, mergePatchValue(old, value))
+	}
+	return targetMap
+}
+
+/*
+合并JSON数组的策略
+*/
+type MergeArrayStrategy int
+
+const (
+	MERGE_ARRAY_REPLACE  = MergeArrayStrategy(iota)  // 整体替换
+	MERGE_ARRAY_CONCAT                               // 把other中的元素追加到后面
+	MERGE_ARRAY_BY_INDEX                             // 相同下标的元素逐个合并，多出的元素追加到后面
+	MERGE_ARRAY_BY_KEY                               // 元素都是JSON对象时，KeyField字段的值相同的元素合并，其它的追加到后面
+)
+
+/*
+两边的值无法合并(类型不同，或者都不是JSON对象、JSON数组)时的处理策略
+*/
+type MergeConflictStrategy int
+
+const (
+	MERGE_CONFLICT_OVERWRITE = MergeConflictStrategy(iota)  // 使用other中的值
+	MERGE_CONFLICT_KEEP                                     // 保留原来的值
+	MERGE_CONFLICT_ERROR                                    // 返回ErrMergeConflict，EasyJSON保持不变
+)
+
+/*
+Merge的选项
+   ArrayStrategy -- 合并JSON数组的策略
+   KeyField      -- ArrayStrategy为MERGE_ARRAY_BY_KEY时，用于对齐数组元素的字段名，例如"id"
+                    如果有元素不是JSON对象或者没有该字段，这个数组整体替换
+   Conflict      -- 两边的值无法合并时的处理策略
+*/
+type MergeOptions struct {
+	ArrayStrategy MergeArrayStrategy
+	KeyField      string
+	Conflict      MergeConflictStrategy
+}
+
+/*
+把other深度合并到EasyJSON中
+JSON对象逐层合并，JSON数组按opts.ArrayStrategy合并，
+与MergePatch不同，other中的null是普通的值，不表示删除
+合并进来的值都是拷贝，之后修改other不会影响EasyJSON
+*/
+func (easyJSON *EasyJSON) Merge(other *EasyJSON, opts MergeOptions) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	if other == nil {
+		return ErrInvalidArguments
+	}
+
+	// 在拷贝上合并，保证出错时EasyJSON保持不变，成功后用拷贝替换EasyJSON的内容
+	if opts.Conflict == MERGE_CONFLICT_ERROR {
+		root, err := mergeValue(cloneValue(easyJSON.root()), other.root(), opts)
+		if err != nil {
+			return err
+		}
+		old := easyJSON.root()  // 没有被修改，不需要拷贝
+		easyJSON.setRoot(root)
+		easyJSON.recordRoot(old)
+		return nil
+	}
+
+	old := easyJSON.snapshot()
+	root, _ := mergeValue(easyJSON.root(), other.root(), opts)
+	easyJSON.setRoot(root)
+	easyJSON.recordRoot(old)
+	return nil
+}
+
+/*
+把src合并到dst中，返回合并后的值
+*/
+func mergeValue(dst, src interface{}, opts MergeOptions) (interface{}, error) {
+	switch srcValue := src.(type) {
+	case *orderedMap:
+		dstMap, ok := dst.(*orderedMap)
+		if !ok {
+			return mergeConflict(dst, src, opts)
+		}
+		for _, k := range srcValue.keys {
+			old, ok := dstMap.get(k)
+			if !ok {
+				dstMap.set(k, cloneValue(srcValue.m[k]))
+				continue
+			}
+			value, err := mergeValue(old, srcValue.m[k], opts)
+			if err != nil {
+				return nil, err
+			}
+			dstMap.set(k, value)
+		}
+		return dstMap, nil
+	case []interface{}:
+		dstArray, ok := dst.([]interface{})
+		if !ok {
+			return mergeConflict(dst, src, opts)
+		}
+		return mergeArray(dstArray, srcValue, opts)
+	}
+
+	if kindOf(dst) != kindOf(src) || !valueEqual(dst, src) {
+		return mergeConflict(dst, src, opts)
+	}
+	return dst, nil
+}
+
+func mergeConflict(dst, src interface{}, opts MergeOptions) (interface{}, error) {
+	switch opts.Conflict {
+	case MERGE_CONFLICT_KEEP:
+		return dst, nil
+	case MERGE_CONFLICT_ERROR:
+		return nil, ErrMergeConflict
+	}
+	return cloneValue(src), nil
+}
+
+func mergeArray(dst, src []interface{}, opts MergeOptions) (interface{}, error) {
+	switch opts.ArrayStrategy {
+	case MERGE_ARRAY_CONCAT:
+		for _, elem := range src {
+			dst = append(dst, cloneValue(elem))
+		}
+		return dst, nil
+	case MERGE_ARRAY_BY_INDEX:
+		for i, elem := range src {
+			if i >= len(dst) {
+				dst = append(dst, cloneValue(elem))
+				continue
+			}
+			value, err := mergeValue(dst[i], elem, opts)
+			if err != nil {
+				return nil, err
+			}
+			dst[i] = value
+		}
+		return dst, nil
+	case MERGE_ARRAY_BY_KEY:
+		dstIndex, ok := indexByKey(dst, opts.KeyField)
+		if !ok {
+			break
+		}
+		if _, ok := indexByKey(src, opts.KeyField); !ok {
+			break
+		}
+
+		for _, elem := range src {
+			i, ok := dstIndex[keyOf(elem, opts.KeyField)]
+			if !ok {
+				dst = append(dst, cloneValue(elem))
+				continue
+			}
+			value, err := mergeValue(dst[i], elem, opts)
+			if err != nil {
+				return nil, err
+			}
+			dst[i] = value
+		}
+		return dst, nil
+	}
+	return cloneValue(src), nil
+}
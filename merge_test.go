@@ -0,0 +1,67 @@
+package EasyJSON
+
+import "testing"
+
+func TestMergePatch(t *testing.T) {
+	doc, _ := Parse(`{"a":1,"b":{"c":1,"d":2},"e":[1]}`)
+	patch, _ := Parse(`{"a":null,"b":{"c":null,"f":3},"e":[2]}`)
+
+	if err := doc.MergePatch(patch); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"b":{"d":2,"f":3},"e":[2]}` {
+		t.Fatalf("got %s", got)
+	}
+	if err := doc.MergePatch(nil); err != ErrInvalidArguments {
+		t.Fatalf("got %v, want ErrInvalidArguments", err)
+	}
+}
+
+func TestMergeStrategies(t *testing.T) {
+	for _, c := range []struct {
+		opts MergeOptions
+		want string
+	}{
+		{MergeOptions{}, `{"a":[{"id":2,"v":"y"}],"b":null}`},
+		{MergeOptions{ArrayStrategy: MERGE_ARRAY_CONCAT}, `{"a":[{"id":1,"v":"x"},{"id":2,"v":"y"}],"b":null}`},
+		{MergeOptions{ArrayStrategy: MERGE_ARRAY_BY_INDEX}, `{"a":[{"id":2,"v":"y"}],"b":null}`},
+		{MergeOptions{ArrayStrategy: MERGE_ARRAY_BY_KEY, KeyField: "id"}, `{"a":[{"id":1,"v":"x"},{"id":2,"v":"y"}],"b":null}`},
+		{MergeOptions{Conflict: MERGE_CONFLICT_KEEP}, `{"a":[{"id":2,"v":"y"}],"b":1}`},
+	} {
+		doc, _ := Parse(`{"a":[{"id":1,"v":"x"}],"b":1}`)
+		other, _ := Parse(`{"a":[{"id":2,"v":"y"}],"b":null}`)
+		if err := doc.Merge(other, c.opts); err != nil {
+			t.Fatalf("%+v: %v", c.opts, err)
+		}
+		if got := doc.String(); got != c.want {
+			t.Errorf("%+v: got %s, want %s", c.opts, got, c.want)
+		}
+	}
+}
+
+func TestMergeConflictError(t *testing.T) {
+	doc, _ := Parse(`{"a":{"b":1},"c":"x"}`)
+	doc.EnableJournal()
+
+	conflict, _ := Parse(`{"a":{"b":2},"c":{"d":1}}`)
+	if err := doc.Merge(conflict, MergeOptions{Conflict: MERGE_CONFLICT_ERROR}); err != ErrMergeConflict {
+		t.Fatalf("got %v, want ErrMergeConflict", err)
+	}
+	if got := doc.String(); got != `{"a":{"b":1},"c":"x"}` {
+		t.Fatalf("document changed by a failed merge: %s", got)
+	}
+
+	other, _ := Parse(`{"a":{"e":2}}`)
+	if err := doc.Merge(other, MergeOptions{Conflict: MERGE_CONFLICT_ERROR}); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":{"b":1,"e":2},"c":"x"}` {
+		t.Fatalf("got %s", got)
+	}
+	if err := doc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":{"b":1},"c":"x"}` {
+		t.Fatalf("got %s after Undo", got)
+	}
+}
@@ -0,0 +1,181 @@
+package EasyJSON
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrLineTooLong = errors.New("line too long")
+
+// LineReader默认的最大行长度
+const DEFAULT_MAX_LINE_LENGTH = 4 * 1024 * 1024
+
+/*
+读取某一行时的错误
+*/
+type LineError struct {
+	Line int  // 行号，从1开始
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+/*
+按行读取NDJSON (JSON Lines)，每行是一个JSON对象或JSON数组
+空行被忽略，行尾的"\r\n"和"\n"都可以
+   reader := EasyJSON.NewLineReader(r)
+   for {
+      record, err := reader.Read()
+      if err == io.EOF {
+         break
+      }
+      ...
+   }
+*/
+type LineReader struct {
+	r             *bufio.Reader
+	line          int
+	skipBadLines  bool
+	maxLineLength int
+	skipped       int
+	err           error  // 读取r时的错误，之后的Read都返回它
+}
+
+func NewLineReader(r io.Reader) *LineReader {
+	return &LineReader{r: bufio.NewReader(r), maxLineLength: DEFAULT_MAX_LINE_LENGTH}
+}
+
+/*
+设置为true时，跳过无法解析或者超过最大长度的行，而不是返回错误
+跳过的行数可以通过Skipped获取
+*/
+func (reader *LineReader) SetSkipBadLines(skipBadLines bool) {
+	reader.skipBadLines = skipBadLines
+}
+
+/*
+设置最大行长度(字节数，不包括换行符)，超过时返回ErrLineTooLong
+maxLineLength <= 0 表示不限制
+*/
+func (reader *LineReader) SetMaxLineLength(maxLineLength int) {
+	reader.maxLineLength = maxLineLength
+}
+
+/*
+返回已经读取的行数，即最近一行的行号
+*/
+func (reader *LineReader) Line() int {
+	return reader.line
+}
+
+/*
+返回因为SetSkipBadLines跳过的行数
+*/
+func (reader *LineReader) Skipped() int {
+	return reader.skipped
+}
+
+/*
+读取下一条记录
+没有更多记录时返回io.EOF
+某一行有错误时返回*LineError，可以继续读取下一行
+*/
+func (reader *LineReader) Read() (*EasyJSON, error) {
+	for {
+		if reader.err != nil {
+			return nil, reader.err
+		}
+
+		line, err := reader.readLine()
+		if err != nil && err != ErrLineTooLong {
+			reader.err = err  // 处理完最后一行之后再返回
+			if err != io.EOF {
+				return nil, err
+			}
+		}
+
+		if err != ErrLineTooLong {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var record *EasyJSON
+			if record, err = Parse(string(line)); err == nil {
+				return record, nil
+			}
+		}
+
+		if reader.skipBadLines {
+			reader.skipped++
+			continue
+		}
+		return nil, &LineError{Line: reader.line, Err: err}
+	}
+}
+
+/*
+读取一行，不包括换行符
+超过最大长度时丢弃这一行剩下的部分，返回ErrLineTooLong
+读到末尾时，返回最后一行(可能为空)以及io.EOF
+*/
+func (reader *LineReader) readLine() ([]byte, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := reader.r.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, chunk...)
+			if reader.maxLineLength > 0 && len(bytes.TrimRight(line, "\r\n")) > reader.maxLineLength {
+				tooLong = true
+				line = nil
+			}
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == nil || len(chunk) > 0 || len(line) > 0 || tooLong {
+			reader.line++
+		}
+		if tooLong {
+			return nil, ErrLineTooLong
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		return line, err
+	}
+}
+
+/*
+按行写入NDJSON (JSON Lines)，每条记录输出为一行紧凑的JSON字符串
+输出选项(SetSortKeys等)使用各条记录自己的设置
+*/
+type LineWriter struct {
+	w io.Writer
+}
+
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w}
+}
+
+/*
+写入一条记录，每条记录只调用一次w.Write
+*/
+func (writer *LineWriter) Write(record *EasyJSON) error {
+	if record == nil {
+		return ErrInvalidArguments
+	}
+
+	buf := getBuffer()
+	*buf = append(record.AppendJSON((*buf)[:0]), '\n')
+	_, err := writer.w.Write(*buf)
+	putBuffer(buf)
+	return err
+}
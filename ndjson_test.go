@@ -0,0 +1,162 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readLines(reader *LineReader) (records []string, errs []error) {
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			errs = append(errs, err)
+			if _, ok := err.(*LineError); !ok {
+				return
+			}
+			continue
+		}
+		records = append(records, record.String())
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	reader := NewLineReader(strings.NewReader("{\"a\":1}\r\n\n  \n[1,2]\n{bad}\n{\"b\":2}"))
+	records, errs := readLines(reader)
+
+	if strings.Join(records, " ") != `{"a":1} [1,2] {"b":2}` {
+		t.Fatalf("got %q", records)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("got errors %v", errs)
+	}
+	lineErr, ok := errs[0].(*LineError)
+	if !ok || lineErr.Line != 5 || lineErr.Err == nil || errors.Unwrap(lineErr) != lineErr.Err {
+		t.Fatalf("got %#v", errs[0])
+	}
+	if !strings.HasPrefix(errs[0].Error(), "line 5: ") {
+		t.Fatalf("got %q", errs[0].Error())
+	}
+	if reader.Line() != 6 || reader.Skipped() != 0 {
+		t.Fatalf("got line %d, skipped %d", reader.Line(), reader.Skipped())
+	}
+
+	// 读到末尾后一直返回io.EOF
+	if _, err := reader.Read(); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestLineReaderSkipBadLines(t *testing.T) {
+	long := `{"s":"` + strings.Repeat("x", 10000) + `"}`
+	text := "{\"a\":1}\n" + long + "\nnot json\n{\"b\":2}\n" + long
+
+	reader := NewLineReader(strings.NewReader(text))
+	reader.SetMaxLineLength(100)
+	records, errs := readLines(reader)
+	if strings.Join(records, " ") != `{"a":1} {"b":2}` || len(errs) != 3 {
+		t.Fatalf("got %q, %v", records, errs)
+	}
+	for i, line := range []int{2, 3, 5} {
+		if lineErr := errs[i].(*LineError); lineErr.Line != line {
+			t.Errorf("got line %d, want %d", lineErr.Line, line)
+		}
+	}
+	if !errors.Is(errs[0], ErrLineTooLong) || !errors.Is(errs[2], ErrLineTooLong) {
+		t.Fatalf("got %v", errs)
+	}
+
+	reader = NewLineReader(strings.NewReader(text))
+	reader.SetMaxLineLength(100)
+	reader.SetSkipBadLines(true)
+	records, errs = readLines(reader)
+	if strings.Join(records, " ") != `{"a":1} {"b":2}` || errs != nil || reader.Skipped() != 3 {
+		t.Fatalf("got %q, %v, skipped %d", records, errs, reader.Skipped())
+	}
+
+	// 不限制长度
+	reader = NewLineReader(strings.NewReader(text))
+	reader.SetMaxLineLength(0)
+	reader.SetSkipBadLines(true)
+	records, _ = readLines(reader)
+	if len(records) != 4 || reader.Skipped() != 1 {
+		t.Fatalf("got %d records, skipped %d", len(records), reader.Skipped())
+	}
+}
+
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestLineReaderError(t *testing.T) {
+	failed := errors.New("failed")
+	reader := NewLineReader(&failingReader{data: "{\"a\":1}\n{\"b\":", err: failed})
+
+	if record, err := reader.Read(); err != nil || record.String() != `{"a":1}` {
+		t.Fatalf("got %v, %v", record, err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := reader.Read(); err != failed {
+			t.Fatalf("got %v, want the reader error", err)
+		}
+	}
+}
+
+type failingWriter struct {
+	writes []string
+	err    error
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), w.err
+}
+
+func TestLineWriter(t *testing.T) {
+	w := &failingWriter{}
+	writer := NewLineWriter(w)
+
+	sorted := Object("b", 1, "a", "\n")
+	sorted.SetSortKeys(true)
+	for _, record := range []*EasyJSON{Object("b", 1, "a", "\n"), sorted, Array()} {
+		if err := writer.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"{\"b\":1,\"a\":\"\\n\"}\n", "{\"a\":\"\\n\",\"b\":1}\n", "[]\n"}
+	if strings.Join(w.writes, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q", w.writes)
+	}
+
+	// 写出的内容可以按行读回
+	var buf bytes.Buffer
+	for _, s := range w.writes {
+		buf.WriteString(s)
+	}
+	if records, errs := readLines(NewLineReader(&buf)); len(records) != 3 || errs != nil {
+		t.Fatalf("got %q, %v", records, errs)
+	}
+
+	if err := writer.Write(nil); err != ErrInvalidArguments {
+		t.Fatalf("got %v, want ErrInvalidArguments", err)
+	}
+	w.err = errors.New("failed")
+	if err := writer.Write(Array()); err != w.err {
+		t.Fatalf("got %v, want the writer error", err)
+	}
+}
@@ -0,0 +1,162 @@
+package EasyJSON
+
+import (
+	"sort"
+)
+
+/*
+保持插入顺序的JSON对象
+Go的map遍历顺序是随机的，导致String()每次输出的字段顺序都不一样，
+所以JSON对象在底层统一用orderedMap表示:
+   keys -- 字段名，按插入(或解析)的先后顺序排列
+   m    -- 字段名到字段值的映射
+*/
+type orderedMap struct {
+	keys []string
+	m    map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{m: make(map[string]interface{})}
+}
+
+/*
+由Go的map生成orderedMap
+map本身没有顺序，所以按字段名排序，保证每次得到的结果一致
+*/
+func orderedMapFromMap(m map[string]interface{}) *orderedMap {
+	om := newOrderedMap()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		om.set(k, m[k])
+	}
+	return om
+}
+
+func (om *orderedMap) get(key string) (interface{}, bool) {
+	value, ok := om.m[key]
+	return value, ok
+}
+
+/*
+设置字段的值
+如果字段已存在，保持原来的位置；否则追加到最后
+*/
+func (om *orderedMap) set(key string, value interface{}) {
+	if _, ok := om.m[key]; !ok {
+		om.keys = append(om.keys, key)
+	}
+	om.m[key] = value
+}
+
+/*
+在第index个位置插入字段，字段已存在时与set相同
+*/
+func (om *orderedMap) insert(index int, key string, value interface{}) {
+	if _, ok := om.m[key]; ok {
+		om.m[key] = value
+		return
+	}
+	if index < 0 || index > len(om.keys) {
+		index = len(om.keys)
+	}
+	om.keys = append(om.keys, "")
+	copy(om.keys[index+1:], om.keys[index:])
+	om.keys[index] = key
+	om.m[key] = value
+}
+
+/*
+删除字段
+返回字段是否存在
+*/
+func (om *orderedMap) delete(key string) bool {
+	if _, ok := om.m[key]; !ok {
+		return false
+	}
+	delete(om.m, key)
+	for i, k := range om.keys {
+		if k == key {
+			om.keys = append(om.keys[:i], om.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+/*
+浅拷贝，字段值不拷贝
+*/
+func (om *orderedMap) shallowCopy() *orderedMap {
+	c := &orderedMap{
+		keys: make([]string, len(om.keys)),
+		m:    make(map[string]interface{}, len(om.m)),
+	}
+	copy(c.keys, om.keys)
+	for k, v := range om.m {
+		c.m[k] = v
+	}
+	return c
+}
+
+func (om *orderedMap) len() int {
+	return len(om.keys)
+}
+
+/*
+返回排好序的字段名列表，不影响原来的顺序
+*/
+func (om *orderedMap) sortedKeys() []string {
+	keys := make([]string, len(om.keys))
+	copy(keys, om.keys)
+	sort.Strings(keys)
+	return keys
+}
+
+/*
+把底层的数据表示转换为Go的原生类型
+JSON对象转换为map[string]interface{}，JSON数组转换为[]interface{}，其它值原样返回
+*/
+func exportValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *orderedMap:
+		m := make(map[string]interface{}, v.len())
+		for _, k := range v.keys {
+			m[k] = exportValue(v.m[k])
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, elem := range v {
+			a[i] = exportValue(elem)
+		}
+		return a
+	}
+	return value
+}
+
+/*
+把底层的数据表示包装为对外的值
+JSON对象和JSON数组包装为*EasyJSON(与原文档共享数据)，其它值原样返回
+parent: 值所属的文档，包装后的*EasyJSON与它的只读状态和输出选项相同，为nil时使用默认值
+*/
+func wrapValue(value interface{}, parent *EasyJSON) interface{} {
+	var view *EasyJSON
+	switch v := value.(type) {
+	case *orderedMap:
+		view = newObject(v)
+	case []interface{}:
+		view = newArray(v)
+	default:
+		return value
+	}
+	if parent != nil {
+		view.frozen = parent.frozen
+		view.opts = parent.opts
+	}
+	return view
+}
@@ -0,0 +1,118 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+/*
+解析JSON字符串，得到底层的数据表示
+与json.Unmarshal不同的是，JSON对象解析为orderedMap，保留字段在源字符串中的顺序
+数字统一解析为float64，与json.Unmarshal保持一致
+*/
+func decodeString(jsonString string) (interface{}, error) {
+	return decodeReader(strings.NewReader(jsonString))
+}
+
+func decodeBytes(data []byte) (interface{}, error) {
+	return decodeReader(bytes.NewReader(data))
+}
+
+func decodeReader(r io.Reader) (interface{}, error) {
+	return decodeAll(json.NewDecoder(r))
+}
+
+/*
+从dec中读取一个JSON值，之后不允许再有其它内容
+*/
+func decodeAll(dec *json.Decoder) (interface{}, error) {
+	value, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON值之后不允许再有其它内容
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrInvalidJSONString
+	}
+
+	return value, nil
+}
+
+/*
+从dec中读取一个完整的JSON值
+*/
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(dec, token)
+}
+
+/*
+已经读取了token，继续读取token所开始的JSON值
+*/
+func decodeToken(dec *json.Decoder, token json.Token) (interface{}, error) {
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			return decodeObject(dec)
+		} else if t == '[' {
+			return decodeArray(dec)
+		}
+		return nil, ErrInvalidJSONString
+	}
+	return token, nil
+}
+
+/*
+读取JSON对象，'{'已经被读取
+*/
+func decodeObject(dec *json.Decoder) (*orderedMap, error) {
+	om := newOrderedMap()
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, ErrInvalidJSONString
+		}
+
+		value, err := decodeValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		om.set(key, value)
+	}
+
+	// 读取'}'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return om, nil
+}
+
+/*
+读取JSON数组，'['已经被读取
+*/
+func decodeArray(dec *json.Decoder) ([]interface{}, error) {
+	a := []interface{}{}
+	for dec.More() {
+		value, err := decodeValue(dec)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, value)
+	}
+
+	// 读取']'
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
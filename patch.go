@@ -0,0 +1,368 @@
+package EasyJSON
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPatch   = errors.New("invalid patch")
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	ErrTestFailed     = errors.New("test operation failed")
+)
+
+/*
+按RFC 6902 (JSON Patch)修改EasyJSON
+patch: JSON数组，每个元素是一个操作，支持add、remove、replace、move、copy、test，例如
+   [
+      {"op": "replace", "path": "/chapters/1/title", "value": "Basic Go"},
+      {"op": "remove", "path": "/publisher"}
+   ]
+所有操作要么全部成功，要么全部不生效:
+先在拷贝上执行，全部成功后用拷贝替换EasyJSON的内容
+*/
+func (easyJSON *EasyJSON) ApplyPatch(patch *EasyJSON) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+	if patch == nil || patch.GetJSONType() != JSON_TYPE_ARRAY {
+		return ErrInvalidPatch
+	}
+
+	elems := patch.root().([]interface{})
+	ops := make([]*orderedMap, 0, len(elems))
+	for _, elem := range elems {
+		op, ok := elem.(*orderedMap)
+		if !ok {
+			return ErrInvalidPatch
+		}
+		ops = append(ops, op)
+	}
+
+	// 在拷贝上执行，失败时EasyJSON不变
+	root := cloneValue(easyJSON.root())
+	for _, op := range ops {
+		var err error
+		if root, err = applyOperation(root, op); err != nil {
+			return err
+		}
+	}
+
+	old := easyJSON.root()  // 没有被修改，不需要拷贝
+	easyJSON.setRoot(root)
+	easyJSON.recordRoot(old)
+	return nil
+}
+
+/*
+执行一个操作，返回执行后的根节点
+*/
+func applyOperation(root interface{}, op *orderedMap) (interface{}, error) {
+	name, err := operationString(op, "op")
+	if err != nil {
+		return nil, err
+	}
+	path, err := operationString(op, "path")
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "add":
+		value, ok := op.get("value")
+		if !ok {
+			return nil, ErrInvalidPatch
+		}
+		return pointerAdd(root, tokens, cloneValue(value))
+	case "remove":
+		if len(tokens) == 0 {  // 不能删除最外层
+			return nil, ErrInvalidPatch
+		}
+		root, _, err = pointerRemove(root, tokens)
+		return root, err
+	case "replace":
+		value, ok := op.get("value")
+		if !ok {
+			return nil, ErrInvalidPatch
+		}
+		return pointerReplace(root, tokens, cloneValue(value))
+	case "move", "copy":
+		from, err := operationString(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		fromTokens, err := parsePointer(from)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		if name == "move" {
+			if from == path {
+				_, err = pointerGet(root, fromTokens)
+				return root, err
+			}
+			// 不能把节点移动到它自己的子节点中
+			if len(fromTokens) == 0 || strings.HasPrefix(path, from+"/") {
+				return nil, ErrInvalidPatch
+			}
+			if root, value, err = pointerRemove(root, fromTokens); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = pointerGet(root, fromTokens); err != nil {
+				return nil, err
+			}
+			value = cloneValue(value)
+		}
+		return pointerAdd(root, tokens, value)
+	case "test":
+		value, ok := op.get("value")
+		if !ok {
+			return nil, ErrInvalidPatch
+		}
+		actual, err := pointerGet(root, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !valueEqual(actual, value) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	}
+	return nil, ErrInvalidPatch
+}
+
+func operationString(op *orderedMap, name string) (string, error) {
+	value, ok := op.get(name)
+	if !ok {
+		return "", ErrInvalidPatch
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", ErrInvalidPatch
+	}
+	return s, nil
+}
+
+/*
+分析RFC 6901 JSON Pointer，返回各级的引用
+   ""          -- 最外层
+   "/a/b~1c/0" -- ["a", "b/c", "0"]
+*/
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, ErrInvalidPointer
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, ErrInvalidPointer
+			}
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+/*
+生成JSON Pointer，与parsePointer相反
+*/
+func formatPointer(parent string, token string) string {
+	return parent + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+/*
+把JSON Pointer中的引用解析为数组下标
+size: 数组长度，allowEnd为true时允许下标等于数组长度(包括"-")
+*/
+func pointerIndex(token string, size int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return size, nil
+	}
+	// 不允许前导0和正负号
+	if token == "" || (len(token) > 1 && token[0] == '0') || token[0] < '0' || token[0] > '9' {
+		return 0, ErrInvalidPointer
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, ErrInvalidPointer
+	}
+	if index > size || (index == size && !allowEnd) {
+		return 0, ErrIndexOutOfBounds
+	}
+	return index, nil
+}
+
+/*
+把JSON Pointer的各级引用转换为nameList，之后与Get、Set一样用lookupValue、updateValue等处理
+引用是数组下标还是字段名由上一级节点的类型决定，所以要沿tokens遍历root
+allowEnd为true时最后一级的数组下标可以等于数组长度(包括"-")
+*/
+func pointerNames(root interface{}, tokens []string, allowEnd bool) ([]string, error) {
+	nameList := make([]string, len(tokens))
+	node := root
+	for i, token := range tokens {
+		last := i == len(tokens)-1
+		switch n := node.(type) {
+		case *orderedMap:
+			nameList[i] = encodeName(token)
+		case []interface{}:
+			index, err := pointerIndex(token, len(n), allowEnd && last)
+			if err != nil {
+				return nil, err
+			}
+			nameList[i] = indexPath("", index)
+		default:
+			return nil, ErrFieldNotExists
+		}
+
+		if !last {
+			var err error
+			if node, err = childValue(node, nameList[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nameList, nil
+}
+
+func pointerGet(root interface{}, tokens []string) (interface{}, error) {
+	nameList, err := pointerNames(root, tokens, false)
+	if err != nil {
+		return nil, err
+	}
+	return lookupValue(root, nameList)
+}
+
+func pointerAdd(root interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return replaceRoot(value)
+	}
+	nameList, err := pointerNames(root, tokens, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateValue(root, nameList, func(parent interface{}, name string) (interface{}, error) {
+		if name[0] != '[' {
+			return setValue(parent, []string{name}, value)
+		}
+		// 插入到数组中，后面的元素依次后移
+		a := append(parent.([]interface{}), nil)
+		index := nameIndex(name)
+		copy(a[index+1:], a[index:])
+		a[index] = value
+		return a, nil
+	})
+}
+
+/*
+删除节点，返回修改后的根节点和被删除的值
+*/
+func pointerRemove(root interface{}, tokens []string) (interface{}, interface{}, error) {
+	nameList, err := pointerNames(root, tokens, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	removed, err := lookupValue(root, nameList)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err = deleteValue(root, nameList)
+	return root, removed, err
+}
+
+func pointerReplace(root interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return replaceRoot(value)
+	}
+	nameList, err := pointerNames(root, tokens, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := lookupValue(root, nameList); err != nil {
+		return nil, err
+	}
+	return setValue(root, nameList, value)
+}
+
+/*
+EasyJSON的最外层只能是JSON对象或JSON数组
+*/
+func replaceRoot(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case *orderedMap, []interface{}:
+		return value, nil
+	}
+	return nil, ErrInvalidPatch
+}
+
+/*
+生成从from到to的RFC 6902 (JSON Patch)
+返回的JSON数组可以直接传给ApplyPatch
+from或to为nil时返回nil，把nil传给ApplyPatch会得到ErrInvalidPatch
+*/
+func CreatePatch(from, to *EasyJSON) *EasyJSON {
+	if from == nil || to == nil {
+		return nil
+	}
+	patch := Array()
+	appendPatch(patch, "", from.root(), to.root())
+	return patch
+}
+
+func appendPatch(patch *EasyJSON, pointer string, fromValue, toValue interface{}) {
+	fromKind, toKind := kindOf(fromValue), kindOf(toValue)
+
+	switch {
+	case fromKind == KIND_OBJECT && toKind == KIND_OBJECT:
+		fromMap, toMap := fromValue.(*orderedMap), toValue.(*orderedMap)
+		for _, k := range fromMap.keys {
+			toElem, ok := toMap.get(k)
+			if !ok {
+				patch.a = append(patch.a, Object("op", "remove", "path", formatPointer(pointer, k)).m)
+				continue
+			}
+			appendPatch(patch, formatPointer(pointer, k), fromMap.m[k], toElem)
+		}
+		for _, k := range toMap.keys {
+			if _, ok := fromMap.get(k); !ok {
+				patch.a = append(patch.a, Object("op", "add", "path", formatPointer(pointer, k), "value", cloneValue(toMap.m[k])).m)
+			}
+		}
+	case fromKind == KIND_ARRAY && toKind == KIND_ARRAY:
+		fromArray, toArray := fromValue.([]interface{}), toValue.([]interface{})
+		n := len(fromArray)
+		if len(toArray) < n {
+			n = len(toArray)
+		}
+		for i := 0; i < n; i++ {
+			appendPatch(patch, formatPointer(pointer, strconv.Itoa(i)), fromArray[i], toArray[i])
+		}
+		// 从后往前删除，避免下标变化
+		for i := len(fromArray) - 1; i >= n; i-- {
+			patch.a = append(patch.a, Object("op", "remove", "path", formatPointer(pointer, strconv.Itoa(i))).m)
+		}
+		for i := n; i < len(toArray); i++ {
+			patch.a = append(patch.a, Object("op", "add", "path", formatPointer(pointer, "-"), "value", cloneValue(toArray[i])).m)
+		}
+	default:
+		if fromKind != toKind || !valueEqual(fromValue, toValue) {
+			patch.a = append(patch.a, Object("op", "replace", "path", pointer, "value", cloneValue(toValue)).m)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package EasyJSON
+
+import "testing"
+
+func TestApplyPatch(t *testing.T) {
+	doc, _ := Parse(`{"a":[1,2],"b":{"c":1},"":{"[0]":1}}`)
+	patch, _ := Parse(`[
+		{"op":"add","path":"/a/1","value":9},
+		{"op":"add","path":"/a/-","value":3},
+		{"op":"remove","path":"/b/c"},
+		{"op":"add","path":"/~1x","value":0},
+		{"op":"move","from":"/a/0","path":"/b/d"},
+		{"op":"copy","from":"//[0]","path":"/e"},
+		{"op":"test","path":"/a","value":[9,2,3]}
+	]`)
+
+	if err := doc.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":[9,2,3],"b":{"d":1},"":{"[0]":1},"/x":0,"e":1}` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	doc, _ := Parse(`{"a":[1,2]}`)
+	doc.EnableJournal()
+	patch, _ := Parse(`[{"op":"add","path":"/b","value":1},{"op":"remove","path":"/a/5"}]`)
+
+	if err := doc.ApplyPatch(patch); err != ErrIndexOutOfBounds {
+		t.Fatalf("got %v, want ErrIndexOutOfBounds", err)
+	}
+	if got := doc.String(); got != `{"a":[1,2]}` {
+		t.Fatalf("document changed by a failed patch: %s", got)
+	}
+	if err := doc.Undo(); err == nil {
+		t.Fatal("a failed patch was recorded in the journal")
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	from, _ := Parse(`{"a":[1,2,3],"b":{"c":1},"d":"x"}`)
+	to, _ := Parse(`{"a":[1,5],"b":{"e":2},"f":null}`)
+
+	patch := CreatePatch(from, to)
+	if err := from.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(to) {
+		t.Fatalf("got %s, want %s", from, to)
+	}
+
+	if CreatePatch(nil, to) != nil || CreatePatch(from, nil) != nil {
+		t.Fatal("expected nil patch for nil arguments")
+	}
+	if err := from.ApplyPatch(CreatePatch(nil, to)); err != ErrInvalidPatch {
+		t.Fatalf("got %v, want ErrInvalidPatch", err)
+	}
+}
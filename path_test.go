@@ -0,0 +1,100 @@
+package EasyJSON
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	doc, _ := Parse(`{"a.b":[{"":1}],"x":{"y":2}}`)
+	for path, want := range map[string]float64{
+		`["a.b"][0][""]`: 1,
+		`x.y`:            2,
+		` x["y"] `:       2,
+	} {
+		if got, err := doc.GetFloat64(path); err != nil || got != want {
+			t.Errorf("GetFloat64(%s): got %v, %v, want %v", path, got, err, want)
+		}
+	}
+
+	for _, path := range []string{"a..b", ".a", "a.", "a[", "a[x]", "a[-1]", `a["b]`, "a[0]b", `a["b"`} {
+		if _, err := doc.Get(path); err != ErrInvalidPath {
+			t.Errorf("Get(%s): got %v, want ErrInvalidPath", path, err)
+		}
+	}
+
+	if value, err := doc.Get(""); err != nil || !valueEqual(value, doc.GetData()) {
+		t.Errorf(`Get(""): got %v, %v`, value, err)
+	}
+}
+
+/*
+带引号的字段名在所有接受path的地方都能使用
+*/
+func TestQuotedPath(t *testing.T) {
+	const text = `{"a.b":{"[0]":1},"*":[2],"":"e"}`
+
+	doc, _ := Parse(text)
+	if err := doc.Set(`["a.b"]["c d"]`, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Append(`["*"]`, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Delete(`[""]`); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a.b":{"[0]":1,"c d":3},"*":[2,4]}` {
+		t.Fatalf("got %s", got)
+	}
+
+	if n, err := GetInt64From([]byte(text), `["a.b"]["[0]"]`); err != nil || n != 1 {
+		t.Errorf("GetInt64From: got %v, %v", n, err)
+	}
+
+	lazy, _ := ParseLazy([]byte(text))
+	if s, err := lazy.GetString(`[""]`); err != nil || s != "e" {
+		t.Errorf("lazy GetString: got %v, %v", s, err)
+	}
+
+	stream := NewArrayStream(strings.NewReader(text), `["*"]`)
+	if value, err := stream.NextValue(); err != nil || !valueEqual(value, 2) {
+		t.Errorf("ArrayStream: got %v, %v", value, err)
+	}
+
+	frozen := doc.Freeze()
+	if next := frozen.With(`["a.b"]["[0]"]`, 5); next == nil || next.OptInt64(`["a.b"]["[0]"]`, 0) != 5 {
+		t.Errorf("With: got %v", next)
+	}
+
+	edit, _ := ParseDocument(text, ParseOptions{})
+	if err := edit.Set(`["a.b"]["[0]"]`, 6); err != nil {
+		t.Fatal(err)
+	}
+	if got := edit.String(); got != `{"a.b":{"[0]":6},"*":[2],"":"e"}` {
+		t.Errorf("Document: got %s", got)
+	}
+}
+
+/*
+Watch中 [*] 和 .* 是通配符，["*"] 是名为"*"的字段
+*/
+func TestQuotedPathWatch(t *testing.T) {
+	doc, _ := Parse(`{"*":1,"a":2}`)
+	var literal, wildcard []string
+	doc.Watch(`["*"]`, func(change Change) {
+		literal = append(literal, change.Path)
+	})
+	doc.Watch(`*`, func(change Change) {
+		wildcard = append(wildcard, change.Path)
+	})
+
+	doc.Set("a", 3)
+	doc.Set(`["*"]`, 4)
+	if len(literal) != 1 || literal[0] != `["*"]` {
+		t.Errorf(`["*"] got %v`, literal)
+	}
+	if len(wildcard) != 2 {
+		t.Errorf(`* got %v`, wildcard)
+	}
+}
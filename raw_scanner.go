@@ -0,0 +1,242 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+/*
+直接在JSON字节上查找，不构造orderedMap和切片
+只检查经过的部分，跳过的值只确定其边界，不做完整的校验
+*/
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+/*
+返回从data[i]开始的字符串之后的位置，data[i]为'"'
+格式错误时返回-1
+*/
+func skipRawString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+/*
+返回从data[i]开始的JSON值之后的位置
+格式错误时返回-1
+*/
+func skipRawValue(data []byte, i int) int {
+	if i >= len(data) {
+		return -1
+	}
+
+	switch data[i] {
+	case '"':
+		return skipRawString(data, i)
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				if i = skipRawString(data, i); i < 0 {
+					return -1
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return -1
+	}
+
+	// 数字、true、false、null
+	start := i
+	for i < len(data) {
+		switch data[i] {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			if i == start {
+				return -1
+			}
+			return i
+		}
+		i++
+	}
+	if i == start {
+		return -1
+	}
+	return i
+}
+
+/*
+按顺序遍历JSON对象的字段，value为字段值的原始字节
+data以'{'开始(前面可以有空白)，否则返回ErrNotAnObject
+fn返回false时停止遍历
+*/
+func rawObjectEach(data []byte, fn func(key string, value []byte) bool) error {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return ErrNotAnObject
+	}
+
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return nil
+	}
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return ErrInvalidJSONString
+		}
+		end := skipRawString(data, i)
+		if end < 0 {
+			return ErrInvalidJSONString
+		}
+		key, err := rawKey(data[i:end])
+		if err != nil {
+			return err
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) || data[i] != ':' {
+			return ErrInvalidJSONString
+		}
+		i = skipSpace(data, i+1)
+		end = skipRawValue(data, i)
+		if end < 0 {
+			return ErrInvalidJSONString
+		}
+		if !fn(key, data[i:end]) {
+			return nil
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) {
+			return ErrInvalidJSONString
+		}
+		if data[i] == '}' {
+			return nil
+		}
+		if data[i] != ',' {
+			return ErrInvalidJSONString
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+/*
+按顺序遍历JSON数组的元素，value为元素的原始字节
+data以'['开始(前面可以有空白)，否则返回ErrNotAnArray
+fn返回false时停止遍历
+*/
+func rawArrayEach(data []byte, fn func(index int, value []byte) bool) error {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '[' {
+		return ErrNotAnArray
+	}
+
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == ']' {
+		return nil
+	}
+	for index := 0; ; index++ {
+		end := skipRawValue(data, i)
+		if end < 0 {
+			return ErrInvalidJSONString
+		}
+		if !fn(index, data[i:end]) {
+			return nil
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) {
+			return ErrInvalidJSONString
+		}
+		if data[i] == ']' {
+			return nil
+		}
+		if data[i] != ',' {
+			return ErrInvalidJSONString
+		}
+		i = skipSpace(data, i+1)
+	}
+}
+
+/*
+把带引号的字段名转换为字符串，没有转义字符时不需要完整的解码
+*/
+func rawKey(quoted []byte) (string, error) {
+	if bytes.IndexByte(quoted, '\\') < 0 {
+		return string(quoted[1 : len(quoted)-1]), nil
+	}
+	var key string
+	if err := json.Unmarshal(quoted, &key); err != nil {
+		return "", ErrInvalidJSONString
+	}
+	return key, nil
+}
+
+/*
+在data中查找nameList处的值，返回其原始字节
+nameList由parsePath得到，错误与get相同
+JSON对象中有重复的字段时，与解析的结果一致，取最后一个
+*/
+func rawLookup(data []byte, nameList []string) ([]byte, error) {
+	for _, name := range nameList {
+		var found []byte
+		var err error
+
+		if name[0] == '[' {  // 表明是数组
+			index, _ := strconv.Atoi(name[1 : len(name)-1])  // 去除前后中括号
+			err = rawArrayEach(data, func(i int, value []byte) bool {
+				if i == index {
+					found = value
+					return false
+				}
+				return true
+			})
+			if err == nil && found == nil {
+				err = ErrIndexOutOfBounds
+			}
+		} else { // 表明是对象
+			field := fieldName(name)
+			err = rawObjectEach(data, func(key string, value []byte) bool {
+				if key == field {
+					found = value
+				}
+				return true
+			})
+			if err == nil && found == nil {
+				err = ErrFieldNotExists
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+		data = found
+	}
+	return data, nil
+}
@@ -0,0 +1,300 @@
+package EasyJSON
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/*
+ParseWithOptions的选项，都为false时与Parse相同
+   AllowComments       -- 允许 // 行注释和 /* 块注释
+   AllowTrailingCommas -- 允许JSON对象、JSON数组的最后一个元素之后有逗号
+   AllowSingleQuotes   -- 允许用单引号括起字符串，其中的 \' 表示单引号
+   AllowUnquotedKeys   -- 允许字段名不加引号，字段名由字母、数字、_、$组成
+   AllowHexNumbers     -- 允许十六进制整数，例如 0x1F
+   AllowLooseNumbers   -- 允许以+号开头、省略整数部分或小数部分的数字，例如 +1、.5、5.
+*/
+type ParseOptions struct {
+	AllowComments       bool
+	AllowTrailingCommas bool
+	AllowSingleQuotes   bool
+	AllowUnquotedKeys   bool
+	AllowHexNumbers     bool
+	AllowLooseNumbers   bool
+}
+
+/*
+按选项放宽JSON语法后解析，适合人工编辑的配置文件
+先转换为标准的JSON再解析，得到的EasyJSON与Parse的完全一样，注释等不会保留
+语法错误时返回*LineError，Line为出错的行号
+*/
+func ParseWithOptions(jsonString string, opts ParseOptions) (*EasyJSON, error) {
+	if opts == (ParseOptions{}) {
+		return Parse(jsonString)
+	}
+
+	t := &relaxedTranslator{src: jsonString, opts: opts, line: 1}
+	if err := t.translate(); err != nil {
+		return nil, err
+	}
+
+	easyJSON, err := Parse(string(t.out))
+	if err != nil {
+		// 转换时保留了所有换行，错误的位置可以换算为行号
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) && syntaxErr.Offset <= int64(len(t.out)) {
+			line := 1 + strings.Count(string(t.out[:syntaxErr.Offset]), "\n")
+			return nil, &LineError{Line: line, Err: err}
+		}
+		return nil, err
+	}
+	return easyJSON, nil
+}
+
+/*
+解析JSON5，即打开ParseOptions的所有选项
+不支持JSON5中的Infinity和NaN，因为它们无法输出为JSON
+*/
+func ParseJSON5(jsonString string) (*EasyJSON, error) {
+	return ParseWithOptions(jsonString, ParseOptions{
+		AllowComments:       true,
+		AllowTrailingCommas: true,
+		AllowSingleQuotes:   true,
+		AllowUnquotedKeys:   true,
+		AllowHexNumbers:     true,
+		AllowLooseNumbers:   true,
+	})
+}
+
+/*
+把放宽语法的JSON转换为标准的JSON
+只处理选项允许的语法，其它内容原样输出，由Parse检查
+*/
+type relaxedTranslator struct {
+	src  string
+	opts ParseOptions
+	out  []byte
+	i    int
+	line int
+}
+
+func (t *relaxedTranslator) translate() error {
+	t.out = make([]byte, 0, len(t.src))
+	for t.i < len(t.src) {
+		c := t.src[t.i]
+		switch {
+		case c == '/' && t.opts.AllowComments:
+			if err := t.skipComment(); err != nil {
+				return err
+			}
+		case c == '"' || (c == '\'' && t.opts.AllowSingleQuotes):
+			if err := t.translateString(c); err != nil {
+				return err
+			}
+		case c == ',' && t.opts.AllowTrailingCommas:
+			t.i++
+			if j := t.skipIgnored(t.i); j < len(t.src) && (t.src[j] == '}' || t.src[j] == ']') {
+				continue
+			}
+			t.out = append(t.out, ',')
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			if err := t.translateNumber(); err != nil {
+				return err
+			}
+		case isIdentifierByte(c):
+			if err := t.translateIdentifier(); err != nil {
+				return err
+			}
+		default:
+			if c == '\n' {
+				t.line++
+			}
+			t.out = append(t.out, c)
+			t.i++
+		}
+	}
+	return nil
+}
+
+func (t *relaxedTranslator) fail() error {
+	return &LineError{Line: t.line, Err: ErrInvalidJSONString}
+}
+
+/*
+跳过注释，换行保留在输出中以便计算行号
+*/
+func (t *relaxedTranslator) skipComment() error {
+	if strings.HasPrefix(t.src[t.i:], "//") {
+		end := strings.IndexByte(t.src[t.i:], '\n')
+		if end < 0 {
+			t.i = len(t.src)
+		} else {
+			t.i += end
+		}
+		t.out = append(t.out, ' ')
+		return nil
+	}
+
+	if strings.HasPrefix(t.src[t.i:], "/*") {
+		end := strings.Index(t.src[t.i+2:], "*/")
+		if end < 0 {
+			return t.fail()
+		}
+		comment := t.src[t.i : t.i+2+end+2]
+		t.out = append(t.out, ' ')
+		for n := strings.Count(comment, "\n"); n > 0; n-- {
+			t.out = append(t.out, '\n')
+			t.line++
+		}
+		t.i += len(comment)
+		return nil
+	}
+
+	return t.fail()
+}
+
+/*
+返回从j开始第一个不是空白(和注释)的位置
+*/
+func (t *relaxedTranslator) skipIgnored(j int) int {
+	for j < len(t.src) {
+		switch t.src[j] {
+		case ' ', '\t', '\r', '\n':
+			j++
+			continue
+		case '/':
+			if !t.opts.AllowComments {
+				return j
+			}
+			if strings.HasPrefix(t.src[j:], "//") {
+				end := strings.IndexByte(t.src[j:], '\n')
+				if end < 0 {
+					return len(t.src)
+				}
+				j += end
+				continue
+			}
+			if strings.HasPrefix(t.src[j:], "/*") {
+				end := strings.Index(t.src[j+2:], "*/")
+				if end < 0 {
+					return len(t.src)
+				}
+				j += 2 + end + 2
+				continue
+			}
+		}
+		return j
+	}
+	return j
+}
+
+/*
+输出为双引号括起的字符串
+*/
+func (t *relaxedTranslator) translateString(quote byte) error {
+	t.out = append(t.out, '"')
+	for t.i++; t.i < len(t.src); t.i++ {
+		c := t.src[t.i]
+		switch {
+		case c == quote:
+			t.out = append(t.out, '"')
+			t.i++
+			return nil
+		case c == '\\':
+			if t.i+1 >= len(t.src) {
+				return t.fail()
+			}
+			t.i++
+			if t.src[t.i] == '\'' && t.opts.AllowSingleQuotes {
+				t.out = append(t.out, '\'')
+			} else {
+				t.out = append(t.out, '\\', t.src[t.i])
+			}
+		case c == '"':  // 单引号字符串中的双引号
+			t.out = append(t.out, '\\', '"')
+		case c == '\n':
+			return t.fail()
+		default:
+			t.out = append(t.out, c)
+		}
+	}
+	return t.fail()
+}
+
+func (t *relaxedTranslator) translateNumber() error {
+	start := t.i
+	for t.i < len(t.src) && isNumberByte(t.src[t.i]) {
+		t.i++
+	}
+	number := t.src[start:t.i]
+
+	sign := ""
+	if number[0] == '-' || number[0] == '+' {
+		if number[0] == '+' && !t.opts.AllowLooseNumbers {
+			return t.fail()
+		}
+		if number[0] == '-' {
+			sign = "-"
+		}
+		number = number[1:]
+	}
+
+	if len(number) > 2 && number[0] == '0' && (number[1] == 'x' || number[1] == 'X') {
+		if !t.opts.AllowHexNumbers {
+			return t.fail()
+		}
+		n, err := strconv.ParseUint(number[2:], 16, 64)
+		if err != nil {
+			return t.fail()
+		}
+		t.out = append(t.out, sign...)
+		t.out = strconv.AppendUint(t.out, n, 10)
+		return nil
+	}
+
+	if t.opts.AllowLooseNumbers {
+		if strings.HasPrefix(number, ".") {
+			number = "0" + number
+		}
+		if dot := strings.IndexByte(number, '.'); dot >= 0 && (dot+1 == len(number) || number[dot+1] == 'e' || number[dot+1] == 'E') {
+			number = number[:dot+1] + "0" + number[dot+1:]
+		}
+	}
+	t.out = append(t.out, sign...)
+	t.out = append(t.out, number...)
+	return nil
+}
+
+/*
+true、false、null原样输出，后面是冒号的标识符作为字段名加上引号
+*/
+func (t *relaxedTranslator) translateIdentifier() error {
+	start := t.i
+	for t.i < len(t.src) && (isIdentifierByte(t.src[t.i]) || (t.src[t.i] >= '0' && t.src[t.i] <= '9')) {
+		t.i++
+	}
+	ident := t.src[start:t.i]
+
+	if j := t.skipIgnored(t.i); t.opts.AllowUnquotedKeys && j < len(t.src) && t.src[j] == ':' {
+		t.out = append(t.out, '"')
+		t.out = append(t.out, ident...)
+		t.out = append(t.out, '"')
+		return nil
+	}
+	if ident == "true" || ident == "false" || ident == "null" {
+		t.out = append(t.out, ident...)
+		return nil
+	}
+	return t.fail()
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isNumberByte(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') ||
+		c == 'x' || c == 'X' || c == '.' || c == '+' || c == '-'
+}
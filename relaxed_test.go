@@ -0,0 +1,100 @@
+package EasyJSON
+
+import "testing"
+
+func TestParseJSON5(t *testing.T) {
+	doc, err := ParseJSON5(`// 配置文件
+{
+	name: 'it\'s "ok"',  /* 单引号字符串 */
+	url: "http://x//y/*z*/",
+	$id_2: 0x1F,
+	neg: -0XFF,
+	nums: [+1, .5, 5., -.5e1, 1.e2,],
+	nested: {a: true, b: null, c: false, /* 多行
+	注释 */ },
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"it's \"ok\"","url":"http://x//y/*z*/","$id_2":31,"neg":-255,` +
+		`"nums":[1,0.5,5,-5,100],"nested":{"a":true,"b":null,"c":false}}`
+	if got := doc.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	for _, c := range []struct {
+		text string
+		opts ParseOptions
+		want string
+	}{
+		{"[1, // x\n2 /* y */]", ParseOptions{AllowComments: true}, `[1,2]`},
+		{`{"a":[1,],}`, ParseOptions{AllowTrailingCommas: true}, `{"a":[1]}`},
+		{`{'a':'"b\'c'}`, ParseOptions{AllowSingleQuotes: true}, `{"a":"\"b'c"}`},
+		{`{a:1, _b$:true}`, ParseOptions{AllowUnquotedKeys: true}, `{"a":1,"_b$":true}`},
+		{`[0x10, -0xa]`, ParseOptions{AllowHexNumbers: true}, `[16,-10]`},
+		{`[+1, .5, 2.]`, ParseOptions{AllowLooseNumbers: true}, `[1,0.5,2]`},
+		{`{"a":[1]}`, ParseOptions{}, `{"a":[1]}`},
+	} {
+		doc, err := ParseWithOptions(c.text, c.opts)
+		if err != nil {
+			t.Errorf("%s: %v", c.text, err)
+			continue
+		}
+		if got := doc.String(); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.text, got, c.want)
+		}
+
+		// 只打开其它选项时是语法错误
+		other := ParseOptions{AllowComments: true, AllowTrailingCommas: true, AllowSingleQuotes: true,
+			AllowUnquotedKeys: true, AllowHexNumbers: true, AllowLooseNumbers: true}
+		switch {
+		case c.opts.AllowComments:
+			other.AllowComments = false
+		case c.opts.AllowTrailingCommas:
+			other.AllowTrailingCommas = false
+		case c.opts.AllowSingleQuotes:
+			other.AllowSingleQuotes = false
+		case c.opts.AllowUnquotedKeys:
+			other.AllowUnquotedKeys = false
+		case c.opts.AllowHexNumbers:
+			other.AllowHexNumbers = false
+		case c.opts.AllowLooseNumbers:
+			other.AllowLooseNumbers = false
+		default:
+			continue
+		}
+		if _, err := ParseWithOptions(c.text, other); err == nil {
+			t.Errorf("%s: parsed without the option", c.text)
+		}
+	}
+}
+
+func TestParseWithOptionsErrors(t *testing.T) {
+	for _, c := range []struct {
+		text string
+		line int
+	}{
+		{"{\n  a: 1,\n  b: /* x\n */ 2\n  c: 3\n}", 5},
+		{"[\n1,\n/* unterminated", 3},
+		{"[\n'abc\n']", 2},
+		{"[\n0xZZ]", 2},
+		{"{\n\n  undefined\n}", 3},
+		{"[1 / 2]", 1},
+	} {
+		_, err := ParseJSON5(c.text)
+		lineErr, ok := err.(*LineError)
+		if !ok || lineErr.Line != c.line {
+			t.Errorf("%q: got %v, want an error on line %d", c.text, err, c.line)
+		}
+	}
+
+	// 没有打开任何选项时与Parse相同
+	if _, err := ParseWithOptions(`[1,]`, ParseOptions{}); err == nil {
+		t.Fatal("expected an error")
+	} else if _, ok := err.(*LineError); ok {
+		t.Fatalf("got %v, want the error from Parse", err)
+	}
+}
@@ -0,0 +1,151 @@
+package schema
+
+import (
+	"github.com/373518155/EasyJSONGo"
+)
+
+const draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// 推断format时依次尝试
+var inferredFormats = []string{"date-time", "email", "uuid"}
+
+// 类型在"type"数组中的顺序
+var typeOrder = []string{"null", "boolean", "integer", "number", "string", "object", "array"}
+
+/*
+由样本文档推断JSON Schema (draft 2020-12)
+   1. 同一位置在各个样本中出现过的类型都会列入"type"，同时有integer和number时只保留number
+   2. 在每个样本中都出现的字段列入"required"
+   3. 所有样本中的字符串都符合date-time、email或uuid时，生成"format"
+   4. JSON数组所有元素的schema合并为"items"
+字段按第一次出现的顺序排列
+*/
+func InferSchema(samples ...*EasyJSON.EasyJSON) *EasyJSON.EasyJSON {
+	root := newInference()
+	for _, sample := range samples {
+		if sample != nil {
+			root.add(sample)
+		}
+	}
+
+	return EasyJSON.Object(append([]interface{}{"$schema", draft202012}, root.schema()...)...)
+}
+
+/*
+同一位置上所有样本值的统计信息
+*/
+type inference struct {
+	types map[string]bool
+
+	objects       int  // 有多少个样本值是JSON对象
+	properties    map[string]*inference
+	propertyOrder []string
+	presence      map[string]int  // 字段在多少个JSON对象中出现
+
+	items *inference
+
+	strings int             // 有多少个样本值是字符串
+	formats map[string]int  // 符合各个format的字符串个数
+}
+
+func newInference() *inference {
+	return &inference{
+		types:      make(map[string]bool),
+		properties: make(map[string]*inference),
+		presence:   make(map[string]int),
+		formats:    make(map[string]int),
+	}
+}
+
+/*
+value: JSON对象和JSON数组为*EasyJSON，其它为原始值
+*/
+func (inf *inference) add(value interface{}) {
+	doc, ok := value.(*EasyJSON.EasyJSON)
+	if !ok {
+		t := typeOf(value)
+		inf.types[t] = true
+		if s, ok := value.(string); ok {
+			inf.strings++
+			for _, format := range inferredFormats {
+				if formats[format](s) {
+					inf.formats[format]++
+				}
+			}
+		}
+		return
+	}
+
+	if doc.GetJSONType() == EasyJSON.JSON_TYPE_OBJECT {
+		inf.types["object"] = true
+		inf.objects++
+		// Entries给出的是原始的字段名，不是路径
+		for name, child := range doc.Entries() {
+			prop, ok := inf.properties[name]
+			if !ok {
+				prop = newInference()
+				inf.properties[name] = prop
+				inf.propertyOrder = append(inf.propertyOrder, name)
+			}
+			inf.presence[name]++
+			prop.add(child)
+		}
+		return
+	}
+
+	inf.types["array"] = true
+	for _, child := range doc.Elements() {
+		if inf.items == nil {
+			inf.items = newInference()
+		}
+		inf.items.add(child)
+	}
+}
+
+/*
+返回推断出的schema，按Object()的参数形式: 字段名、值、字段名、值 ...
+*/
+func (inf *inference) schema() []interface{} {
+	var schema []interface{}
+
+	var types []interface{}
+	for _, t := range typeOrder {
+		if !inf.types[t] || (t == "integer" && inf.types["number"]) {
+			continue
+		}
+		types = append(types, t)
+	}
+	if len(types) == 1 {
+		schema = append(schema, "type", types[0])
+	} else if len(types) > 1 {
+		schema = append(schema, "type", EasyJSON.Array(types...))
+	}
+
+	if inf.strings > 0 {
+		for _, format := range inferredFormats {
+			if inf.formats[format] == inf.strings {
+				schema = append(schema, "format", format)
+				break
+			}
+		}
+	}
+
+	if inf.objects > 0 {
+		var properties, required []interface{}
+		for _, name := range inf.propertyOrder {
+			properties = append(properties, name, EasyJSON.Object(inf.properties[name].schema()...))
+			if inf.presence[name] == inf.objects {
+				required = append(required, name)
+			}
+		}
+		schema = append(schema, "properties", EasyJSON.Object(properties...))
+		if len(required) > 0 {
+			schema = append(schema, "required", EasyJSON.Array(required...))
+		}
+	}
+
+	if inf.items != nil {
+		schema = append(schema, "items", EasyJSON.Object(inf.items.schema()...))
+	}
+	return schema
+}
@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/373518155/EasyJSONGo"
+)
+
+func TestInferSchema(t *testing.T) {
+	a, _ := EasyJSON.Parse(`{"id":1,"email":"a@example.com","tags":["x"],"at":"2024-01-02T03:04:05Z"}`)
+	b, _ := EasyJSON.Parse(`{"id":2.5,"email":"b@example.com","tags":[]}`)
+
+	got := InferSchema(a, b).String()
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+		`"id":{"type":"number"},"email":{"type":"string","format":"email"},` +
+		`"tags":{"type":"array","items":{"type":"string"}},` +
+		`"at":{"type":"string","format":"date-time"}},"required":["id","email","tags"]}`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestInferSchemaSpecialKeys(t *testing.T) {
+	doc, _ := EasyJSON.Parse(`{"a.b":1,"c[0]":"x","*":true,"":null,"n":{"[":[1]}}`)
+	inferred := InferSchema(doc)
+
+	props, err := inferred.GetObject("properties")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range props.Entries() {
+		names = append(names, name)
+	}
+	want := []string{"a.b", "c[0]", "*", "", "n"}
+	if len(names) != len(want) {
+		t.Fatalf("got properties %q, want %q", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got properties %q, want %q", names, want)
+		}
+	}
+
+	nested, _ := props.GetObject("n.properties")
+	if !nested.Exists(`["["]`) {
+		t.Fatalf("nested property missing: %s", nested)
+	}
+
+	// 推断出的schema能校验样本本身
+	s, err := Compile(inferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := s.Validate(doc); len(errs) != 0 {
+		t.Fatalf("sample does not validate: %v", errs)
+	}
+}
@@ -0,0 +1,495 @@
+/*
+JSON Schema (draft 2020-12) 校验
+
+	s, err := schema.Compile(schemaJSON)
+	errs := s.Validate(doc)
+
+支持的关键字:
+   type, enum, const,
+   properties, patternProperties, additionalProperties, required, minProperties, maxProperties,
+   prefixItems, items, minItems, maxItems, uniqueItems,
+   minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf,
+   minLength, maxLength, pattern, format,
+   allOf, anyOf, oneOf, not, $ref(只支持文档内部的引用，例如"#/$defs/address"), $defs
+其它关键字会被忽略
+*/
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/373518155/EasyJSONGo"
+	"github.com/373518155/EasyJSONGo/internal/jsonutil"
+)
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+/*
+编译后的JSON Schema
+*/
+type Schema struct {
+	boolean *bool  // true、false这样的布尔型schema
+
+	types    []string
+	enum     []interface{}
+	constant interface{}
+	hasConst bool
+
+	properties           map[string]*Schema
+	propertyNames        []string  // properties的字段名，排好序，保证错误的顺序固定
+	patternProperties    []patternProperty
+	additionalProperties *Schema
+	required             []string
+	minProperties        *int
+	maxProperties        *int
+
+	prefixItems []*Schema
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+
+	ref       string
+	refSchema *Schema
+}
+
+type patternProperty struct {
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+/*
+编译JSON Schema
+如果schema不符合规范，或者$ref引用的位置不存在，返回的error包含ErrInvalidSchema
+*/
+func Compile(schema *EasyJSON.EasyJSON) (*Schema, error) {
+	if schema == nil {
+		return nil, ErrInvalidSchema
+	}
+
+	c := &compiler{root: schema.GetData(), refs: make(map[string]*Schema)}
+	s, err := c.compile(c.root, "#")
+	if err != nil {
+		return nil, err
+	}
+
+	// 编译所有被引用的schema，可能会引入新的引用
+	for len(c.pending) > 0 {
+		ref := c.pending[0]
+		c.pending = c.pending[1:]
+
+		target, err := resolvePointer(c.root, ref)
+		if err != nil {
+			return nil, err
+		}
+		compiled, err := c.compile(target, ref)
+		if err != nil {
+			return nil, err
+		}
+		*c.refs[ref] = *compiled
+	}
+
+	if err := checkRefCycles(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+/*
+检查$ref的循环引用
+不经过properties、items等下一级的关键字就回到自身的引用(例如{"$ref": "#"})，
+校验时会在同一个值上无限递归
+循环可能出现在任何一级(例如{"properties": {"a": {"$ref": "#/properties/a"}}})，
+所以要从每个schema开始检查
+*/
+func checkRefCycles(root *Schema) error {
+	const visiting, visited = 1, 2
+	state := make(map[*Schema]int)
+
+	var visit func(s *Schema, ref string) error
+	visit = func(s *Schema, ref string) error {
+		switch state[s] {
+		case visiting:
+			return invalid(ref, "$ref refers to itself without descending into a property or item")
+		case visited:
+			return nil
+		}
+		state[s] = visiting
+
+		// 与s校验同一个值的schema
+		if s.refSchema != nil {
+			if err := visit(s.refSchema, s.ref); err != nil {
+				return err
+			}
+		}
+		for _, list := range [][]*Schema{s.allOf, s.anyOf, s.oneOf} {
+			for _, sub := range list {
+				if err := visit(sub, ref); err != nil {
+					return err
+				}
+			}
+		}
+		if s.not != nil {
+			if err := visit(s.not, ref); err != nil {
+				return err
+			}
+		}
+
+		state[s] = visited
+		return nil
+	}
+
+	seen := map[*Schema]bool{root: true}
+	queue := []*Schema{root}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if err := visit(s, "#"); err != nil {
+			return err
+		}
+		for _, sub := range s.subschemas() {
+			if !seen[sub] {
+				seen[sub] = true
+				queue = append(queue, sub)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+s直接包含的所有schema，包括$ref引用的schema
+*/
+func (s *Schema) subschemas() []*Schema {
+	var list []*Schema
+	for _, name := range s.propertyNames {
+		list = append(list, s.properties[name])
+	}
+	for _, prop := range s.patternProperties {
+		list = append(list, prop.schema)
+	}
+	list = append(list, s.prefixItems...)
+	list = append(list, s.allOf...)
+	list = append(list, s.anyOf...)
+	list = append(list, s.oneOf...)
+	for _, sub := range []*Schema{s.additionalProperties, s.items, s.not, s.refSchema} {
+		if sub != nil {
+			list = append(list, sub)
+		}
+	}
+	return list
+}
+
+type compiler struct {
+	root    interface{}
+	refs    map[string]*Schema  // $ref到编译结果的映射，先占位，编译完成后填充
+	pending []string            // 还没有编译的$ref
+}
+
+/*
+返回$ref对应的schema
+为了支持递归引用，先返回占位的Schema，等所有schema都编译完成后再填充
+*/
+func (c *compiler) reference(ref string) *Schema {
+	if s, ok := c.refs[ref]; ok {
+		return s
+	}
+	s := &Schema{}
+	c.refs[ref] = s
+	c.pending = append(c.pending, ref)
+	return s
+}
+
+func (c *compiler) compile(value interface{}, location string) (*Schema, error) {
+	if b, ok := value.(bool); ok {
+		return &Schema{boolean: &b}, nil
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, invalid(location, "schema must be an object or a boolean")
+	}
+
+	s := &Schema{}
+	var err error
+
+	if v, ok := m["$ref"]; ok {
+		ref, ok := v.(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return nil, invalid(location, "$ref must be a reference within the document")
+		}
+		s.ref = ref
+		s.refSchema = c.reference(ref)
+	}
+
+	if v, ok := m["type"]; ok {
+		switch v := v.(type) {
+		case string:
+			s.types = []string{v}
+		case []interface{}:
+			for _, t := range v {
+				name, ok := t.(string)
+				if !ok {
+					return nil, invalid(location, "type must be a string or an array of strings")
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return nil, invalid(location, "type must be a string or an array of strings")
+		}
+		for _, t := range s.types {
+			switch t {
+			case "null", "boolean", "object", "array", "number", "string", "integer":
+			default:
+				return nil, invalid(location, "unknown type "+strconv.Quote(t))
+			}
+		}
+	}
+
+	if v, ok := m["enum"]; ok {
+		if s.enum, ok = v.([]interface{}); !ok {
+			return nil, invalid(location, "enum must be an array")
+		}
+	}
+	if v, ok := m["const"]; ok {
+		s.constant, s.hasConst = v, true
+	}
+
+	if v, ok := m["properties"]; ok {
+		props, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, invalid(location, "properties must be an object")
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = c.compile(prop, location+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+			s.propertyNames = append(s.propertyNames, name)
+		}
+		sort.Strings(s.propertyNames)
+	}
+	if v, ok := m["patternProperties"]; ok {
+		props, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, invalid(location, "patternProperties must be an object")
+		}
+		patterns := make([]string, 0, len(props))
+		for pattern := range props {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, invalid(location, "invalid pattern "+strconv.Quote(pattern))
+			}
+			prop, err := c.compile(props[pattern], location+"/patternProperties/"+escapePointer(pattern))
+			if err != nil {
+				return nil, err
+			}
+			s.patternProperties = append(s.patternProperties, patternProperty{re, prop})
+		}
+	}
+	if v, ok := m["additionalProperties"]; ok {
+		if s.additionalProperties, err = c.compile(v, location+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := m["required"]; ok {
+		names, ok := v.([]interface{})
+		if !ok {
+			return nil, invalid(location, "required must be an array of strings")
+		}
+		for _, name := range names {
+			name, ok := name.(string)
+			if !ok {
+				return nil, invalid(location, "required must be an array of strings")
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	if v, ok := m["prefixItems"]; ok {
+		if s.prefixItems, err = c.compileList(v, location+"/prefixItems"); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := m["items"]; ok {
+		if s.items, err = c.compile(v, location+"/items"); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := m["uniqueItems"]; ok {
+		if s.uniqueItems, ok = v.(bool); !ok {
+			return nil, invalid(location, "uniqueItems must be a boolean")
+		}
+	}
+
+	counts := []struct {
+		name   string
+		target **int
+	}{
+		{"minProperties", &s.minProperties}, {"maxProperties", &s.maxProperties},
+		{"minItems", &s.minItems}, {"maxItems", &s.maxItems},
+		{"minLength", &s.minLength}, {"maxLength", &s.maxLength},
+	}
+	for _, count := range counts {
+		v, ok := m[count.name]
+		if !ok {
+			continue
+		}
+		f, ok := jsonutil.ToFloat64(v)
+		if !ok || f < 0 || f != math.Trunc(f) {
+			return nil, invalid(location, count.name+" must be a non-negative integer")
+		}
+		n := int(f)
+		*count.target = &n
+	}
+
+	limits := []struct {
+		name   string
+		target **float64
+	}{
+		{"minimum", &s.minimum}, {"maximum", &s.maximum},
+		{"exclusiveMinimum", &s.exclusiveMinimum}, {"exclusiveMaximum", &s.exclusiveMaximum},
+		{"multipleOf", &s.multipleOf},
+	}
+	for _, limit := range limits {
+		v, ok := m[limit.name]
+		if !ok {
+			continue
+		}
+		f, ok := jsonutil.ToFloat64(v)
+		if !ok {
+			return nil, invalid(location, limit.name+" must be a number")
+		}
+		*limit.target = &f
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, invalid(location, "multipleOf must be greater than 0")
+	}
+
+	if v, ok := m["pattern"]; ok {
+		pattern, ok := v.(string)
+		if !ok {
+			return nil, invalid(location, "pattern must be a string")
+		}
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, invalid(location, "invalid pattern "+strconv.Quote(pattern))
+		}
+	}
+	if v, ok := m["format"]; ok {
+		if s.format, ok = v.(string); !ok {
+			return nil, invalid(location, "format must be a string")
+		}
+	}
+
+	combinators := []struct {
+		name   string
+		target *[]*Schema
+	}{
+		{"allOf", &s.allOf}, {"anyOf", &s.anyOf}, {"oneOf", &s.oneOf},
+	}
+	for _, combinator := range combinators {
+		v, ok := m[combinator.name]
+		if !ok {
+			continue
+		}
+		if *combinator.target, err = c.compileList(v, location+"/"+combinator.name); err != nil {
+			return nil, err
+		}
+		if len(*combinator.target) == 0 {
+			return nil, invalid(location, combinator.name+" must not be empty")
+		}
+	}
+	if v, ok := m["not"]; ok {
+		if s.not, err = c.compile(v, location+"/not"); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (c *compiler) compileList(value interface{}, location string) ([]*Schema, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, invalid(location, "must be an array of schemas")
+	}
+
+	schemas := make([]*Schema, 0, len(list))
+	for i, elem := range list {
+		s, err := c.compile(elem, location+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+func invalid(location string, message string) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidSchema, location, message)
+}
+
+/*
+解析文档内部的引用，例如"#/$defs/address"
+*/
+func resolvePointer(root interface{}, ref string) (interface{}, error) {
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return root, nil
+	}
+	if pointer[0] != '/' {
+		return nil, invalid(ref, "unsupported reference")
+	}
+
+	value := root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := value.(type) {
+		case map[string]interface{}:
+			v, ok := node[token]
+			if !ok {
+				return nil, invalid(ref, "reference not found")
+			}
+			value = v
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, invalid(ref, "reference not found")
+			}
+			value = node[index]
+		default:
+			return nil, invalid(ref, "reference not found")
+		}
+	}
+	return value, nil
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
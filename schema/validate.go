@@ -0,0 +1,327 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/373518155/EasyJSONGo"
+	"github.com/373518155/EasyJSONGo/internal/jsonutil"
+)
+
+/*
+校验错误
+   Path    -- 出错的值在文档中的路径，与EasyJSON的Get、Set的path语法相同，""表示最外层
+   Keyword -- 没有通过校验的关键字，例如"required"
+   Message -- 错误描述
+*/
+type ValidationError struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + e.Message
+}
+
+/*
+校验文档，返回所有的校验错误
+如果文档符合schema，返回nil
+*/
+func (s *Schema) Validate(doc *EasyJSON.EasyJSON) []ValidationError {
+	if doc == nil {
+		return []ValidationError{{Keyword: "type", Message: "document is nil"}}
+	}
+	return s.validate(doc.GetData(), "")
+}
+
+func (s *Schema) validate(value interface{}, path string) []ValidationError {
+	if s.boolean != nil {
+		if *s.boolean {
+			return nil
+		}
+		return []ValidationError{{path, "false", "no value is allowed here"}}
+	}
+
+	v := &validation{path: path}
+
+	if s.refSchema != nil {
+		v.errs = append(v.errs, s.refSchema.validate(value, path)...)
+	}
+
+	if len(s.types) > 0 && !matchesType(value, s.types) {
+		v.fail("type", "expected %s, got %s", strings.Join(s.types, " or "), typeOf(value))
+	}
+	if s.enum != nil {
+		found := false
+		for _, elem := range s.enum {
+			if equal(value, elem) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail("enum", "value is not one of the allowed values")
+		}
+	}
+	if s.hasConst && !equal(value, s.constant) {
+		v.fail("const", "value does not equal the constant")
+	}
+
+	switch x := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, x)
+	case []interface{}:
+		s.validateArray(v, x)
+	case string:
+		s.validateString(v, x)
+	default:
+		if f, ok := jsonutil.ToFloat64(value); ok {
+			s.validateNumber(v, value, f)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		v.errs = append(v.errs, sub.validate(value, path)...)
+	}
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.validate(value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail("anyOf", "value does not match any of the schemas")
+		}
+	}
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if len(sub.validate(value, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail("oneOf", "value matches %d of the schemas, expected exactly 1", matched)
+		}
+	}
+	if s.not != nil && len(s.not.validate(value, path)) == 0 {
+		v.fail("not", "value must not match the schema")
+	}
+
+	return v.errs
+}
+
+type validation struct {
+	path string
+	errs []ValidationError
+}
+
+func (v *validation) fail(keyword string, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{v.path, keyword, fmt.Sprintf(format, args...)})
+}
+
+func (s *Schema) validateObject(v *validation, m map[string]interface{}) {
+	for _, name := range s.required {
+		if _, ok := m[name]; !ok {
+			v.fail("required", "missing required property %s", strconv.Quote(name))
+		}
+	}
+	if s.minProperties != nil && len(m) < *s.minProperties {
+		v.fail("minProperties", "expected at least %d properties, got %d", *s.minProperties, len(m))
+	}
+	if s.maxProperties != nil && len(m) > *s.maxProperties {
+		v.fail("maxProperties", "expected at most %d properties, got %d", *s.maxProperties, len(m))
+	}
+
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := jsonutil.JoinPath(v.path, name)
+		matched := false
+
+		if prop, ok := s.properties[name]; ok {
+			matched = true
+			v.errs = append(v.errs, prop.validate(m[name], path)...)
+		}
+		for _, pp := range s.patternProperties {
+			if pp.pattern.MatchString(name) {
+				matched = true
+				v.errs = append(v.errs, pp.schema.validate(m[name], path)...)
+			}
+		}
+		if !matched && s.additionalProperties != nil {
+			if s.additionalProperties.boolean != nil && !*s.additionalProperties.boolean {
+				v.errs = append(v.errs, ValidationError{path, "additionalProperties", "additional property " + strconv.Quote(name) + " is not allowed"})
+				continue
+			}
+			v.errs = append(v.errs, s.additionalProperties.validate(m[name], path)...)
+		}
+	}
+}
+
+func (s *Schema) validateArray(v *validation, a []interface{}) {
+	if s.minItems != nil && len(a) < *s.minItems {
+		v.fail("minItems", "expected at least %d items, got %d", *s.minItems, len(a))
+	}
+	if s.maxItems != nil && len(a) > *s.maxItems {
+		v.fail("maxItems", "expected at most %d items, got %d", *s.maxItems, len(a))
+	}
+	if s.uniqueItems {
+	unique:
+		for i := 0; i < len(a); i++ {
+			for j := i + 1; j < len(a); j++ {
+				if equal(a[i], a[j]) {
+					v.fail("uniqueItems", "items at index %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	for i, elem := range a {
+		path := jsonutil.IndexPath(v.path, i)
+		if i < len(s.prefixItems) {
+			v.errs = append(v.errs, s.prefixItems[i].validate(elem, path)...)
+		} else if s.items != nil {
+			v.errs = append(v.errs, s.items.validate(elem, path)...)
+		}
+	}
+}
+
+func (s *Schema) validateString(v *validation, str string) {
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		v.fail("minLength", "expected at least %d characters, got %d", *s.minLength, length)
+	}
+	if s.maxLength != nil && length > *s.maxLength {
+		v.fail("maxLength", "expected at most %d characters, got %d", *s.maxLength, length)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		v.fail("pattern", "value does not match pattern %s", strconv.Quote(s.pattern.String()))
+	}
+	if s.format != "" {
+		if check, ok := formats[s.format]; ok && !check(str) {
+			v.fail("format", "value is not a valid %s", s.format)
+		}
+	}
+}
+
+/*
+value: 数字本身，f: 转换为float64的值
+*/
+func (s *Schema) validateNumber(v *validation, value interface{}, f float64) {
+	if s.minimum != nil && f < *s.minimum {
+		v.fail("minimum", "expected a value >= %v, got %v", *s.minimum, f)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		v.fail("maximum", "expected a value <= %v, got %v", *s.maximum, f)
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		v.fail("exclusiveMinimum", "expected a value > %v, got %v", *s.exclusiveMinimum, f)
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		v.fail("exclusiveMaximum", "expected a value < %v, got %v", *s.exclusiveMaximum, f)
+	}
+	if s.multipleOf != nil && !isMultipleOf(value, *s.multipleOf) {
+		v.fail("multipleOf", "expected a multiple of %v, got %v", *s.multipleOf, f)
+	}
+}
+
+/*
+按十进制精确判断value是否为divisor的整数倍
+用float64相除会有误差，例如0.3 / 0.1得到2.9999999999999996
+*/
+func isMultipleOf(value interface{}, divisor float64) bool {
+	r, ok := toRat(value)
+	if !ok {
+		return false
+	}
+	d, ok := toRat(divisor)
+	if !ok || d.Sign() == 0 {
+		return false
+	}
+	return r.Quo(r, d).IsInt()
+}
+
+/*
+把数字转换为精确的有理数
+浮点数按最短的十进制表示转换，解析得到的数字与JSON中的写法一致，例如0.1为1/10
+*/
+func toRat(value interface{}) (*big.Rat, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetUint64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, false
+		}
+		return new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, v.Type().Bits()))
+	}
+	return nil, false
+}
+
+func matchesType(value interface{}, types []string) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual {
+			return true
+		}
+		if t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+返回值的JSON Schema类型，没有小数部分的数字为"integer"
+*/
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if f, ok := jsonutil.ToFloat64(value); ok {
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "unknown"
+}
+
+/*
+按JSON的语义比较两个值，数字按数值比较
+*/
+func equal(a, b interface{}) bool {
+	return EasyJSON.Array(a).Equal(EasyJSON.Array(b))
+}
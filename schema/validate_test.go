@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/373518155/EasyJSONGo"
+)
+
+func compile(t *testing.T, text string) *Schema {
+	t.Helper()
+	doc, err := EasyJSON.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Compile(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestValidateMultipleOf(t *testing.T) {
+	s := compile(t, `{"type":"array","items":{"multipleOf":0.1}}`)
+
+	valid := EasyJSON.Array(0.3, 0.7, 1.1, 3, int64(20), -0.2)
+	if errs := s.Validate(valid); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	invalid := EasyJSON.Array(0.35, 0.01)
+	if errs := s.Validate(invalid); len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+}
+
+func TestCompileRefCycle(t *testing.T) {
+	for _, text := range []string{
+		`{"$ref":"#"}`,
+		`{"allOf":[{"$ref":"#"}]}`,
+		`{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"not":{"$ref":"#/$defs/a"}}},"$ref":"#/$defs/a"}`,
+		// 循环出现在下一级中
+		`{"properties":{"a":{"$ref":"#/properties/a"}}}`,
+		`{"items":{"$ref":"#/items"}}`,
+		`{"type":"object","additionalProperties":{"anyOf":[{"$ref":"#/additionalProperties"}]}}`,
+	} {
+		doc, _ := EasyJSON.Parse(text)
+		if _, err := Compile(doc); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("%s: got %v, want ErrInvalidSchema", text, err)
+		}
+	}
+
+	// 经过properties的递归引用是允许的
+	s := compile(t, `{"type":"object","properties":{"next":{"$ref":"#"}}}`)
+	doc, _ := EasyJSON.Parse(`{"next":{"next":{"next":1}}}`)
+	errs := s.Validate(doc)
+	if len(errs) != 1 || errs[0].Path != "next.next.next" {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
@@ -0,0 +1,192 @@
+package EasyJSON
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+/*
+流式读取一个JSON数组的元素，适合处理无法整个放入内存的大文件
+只有当前元素在内存中，其它内容边读取边丢弃
+   stream := EasyJSON.NewArrayStream(r, "data.items")
+   for {
+      item, err := stream.Next()
+      if err == io.EOF {
+         break
+      }
+      ...
+   }
+path之外的内容只做词法检查，数组之后的内容不再读取
+*/
+type ArrayStream struct {
+	dec      *json.Decoder
+	nameList []string
+	started  bool  // 是否已经找到了数组
+	index    int   // 下一个元素的下标
+	err      error // 出错或结束后的Next都返回它
+}
+
+/*
+path: 数组的路径，与Get、Set的path语法相同，""表示最外层的JSON数组
+path格式不正确时，Next返回ErrInvalidPath
+*/
+func NewArrayStream(r io.Reader, path string) *ArrayStream {
+	stream := &ArrayStream{dec: json.NewDecoder(r)}
+	stream.nameList, stream.err = parsePath(path)
+	return stream
+}
+
+/*
+读取下一个元素，元素必须是JSON对象或JSON数组，否则返回*DecodeError
+读完所有元素后返回io.EOF
+*/
+func (stream *ArrayStream) Next() (*EasyJSON, error) {
+	offset := stream.dec.InputOffset()
+	value, err := stream.NextValue()
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := value.(*EasyJSON)
+	if !ok {
+		return nil, &DecodeError{Offset: offset, Err: ErrInvalidJSONString}
+	}
+	return doc, nil
+}
+
+/*
+读取下一个元素，可以是任意的JSON值
+JSON对象和JSON数组以*EasyJSON表示，其它值原样返回
+读完所有元素后返回io.EOF
+*/
+func (stream *ArrayStream) NextValue() (interface{}, error) {
+	if stream.err != nil {
+		return nil, stream.err
+	}
+
+	if !stream.started {
+		if err := stream.descend(); err != nil {
+			stream.err = err
+			return nil, err
+		}
+		stream.started = true
+	}
+
+	if !stream.dec.More() {
+		// 读取']'
+		if _, err := stream.dec.Token(); err != nil {
+			stream.err = err
+			return nil, err
+		}
+		stream.err = io.EOF
+		return nil, io.EOF
+	}
+
+	value, err := decodeValue(stream.dec)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		stream.err = err
+		return nil, err
+	}
+	stream.index++
+	return wrapValue(value, nil), nil
+}
+
+/*
+返回最近一次读取的元素的下标
+*/
+func (stream *ArrayStream) Index() int {
+	return stream.index - 1
+}
+
+/*
+读取到path处的数组，'['已经被读取
+*/
+func (stream *ArrayStream) descend() error {
+	for _, name := range stream.nameList {
+		if name[0] == '[' {  // 表明是数组
+			index, _ := strconv.Atoi(name[1 : len(name)-1])
+			if err := stream.expect('[', ErrNotAnArray); err != nil {
+				return err
+			}
+			for i := 0; i < index; i++ {
+				if !stream.dec.More() {
+					return ErrIndexOutOfBounds
+				}
+				if err := skipValue(stream.dec); err != nil {
+					return err
+				}
+			}
+			if !stream.dec.More() {
+				return ErrIndexOutOfBounds
+			}
+			continue
+		}
+
+		// 表明是对象
+		if err := stream.expect('{', ErrNotAnObject); err != nil {
+			return err
+		}
+		for {
+			if !stream.dec.More() {
+				return ErrFieldNotExists
+			}
+			token, err := stream.dec.Token()
+			if err != nil {
+				return err
+			}
+			if token == fieldName(name) {
+				break
+			}
+			if err := skipValue(stream.dec); err != nil {
+				return err
+			}
+		}
+	}
+	return stream.expect('[', ErrNotAnArray)
+}
+
+/*
+读取一个token，它必须是delim，否则返回mismatch
+*/
+func (stream *ArrayStream) expect(delim json.Delim, mismatch error) error {
+	token, err := stream.dec.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return mismatch
+	}
+	return nil
+}
+
+/*
+跳过一个完整的JSON值，不保存其中的内容
+*/
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package EasyJSON
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestArrayStream(t *testing.T) {
+	text := `{"meta":{"items":[0]},"data":[{"x":1},{"items":[{"id":1},[2],3,"s",null]}],"tail":`
+	stream := NewArrayStream(strings.NewReader(text), `data[1]["items"]`)
+
+	var got []string
+	for {
+		value, err := stream.NextValue()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc, ok := value.(*EasyJSON); ok {
+			got = append(got, doc.String())
+		} else {
+			got = append(got, Array(value).String())
+		}
+		if stream.Index() != len(got)-1 {
+			t.Fatalf("got index %d, want %d", stream.Index(), len(got)-1)
+		}
+	}
+	if strings.Join(got, " ") != `{"id":1} [2] [3] ["s"] [null]` {
+		t.Fatalf("got %q", got)
+	}
+	// 数组之后的内容不再读取，读完后一直返回io.EOF
+	if _, err := stream.Next(); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestArrayStreamNext(t *testing.T) {
+	stream := NewArrayStream(strings.NewReader(`[{"a":1}, 2, []]`), "")
+
+	if doc, err := stream.Next(); err != nil || doc.String() != `{"a":1}` {
+		t.Fatalf("got %v, %v", doc, err)
+	}
+	_, err := stream.Next()
+	if decodeErr, ok := err.(*DecodeError); !ok || decodeErr.Err != ErrInvalidJSONString || decodeErr.Offset != 8 {
+		t.Fatalf("got %#v", err)
+	}
+	// 跳过不是JSON对象或JSON数组的元素后可以继续读取
+	if doc, err := stream.Next(); err != nil || doc.String() != `[]` || stream.Index() != 2 {
+		t.Fatalf("got %v, %v", doc, err)
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Fatalf("got %v, want io.EOF", err)
+	}
+}
+
+func TestArrayStreamErrors(t *testing.T) {
+	for _, c := range []struct {
+		text, path string
+		err        error  // nil表示任意的语法错误
+	}{
+		{`[1]`, "a[", ErrInvalidPath},
+		{`{"a":1}`, "", ErrNotAnArray},
+		{`[1]`, "a", ErrNotAnObject},
+		{`{"a":{"b":[]}}`, "a.c", ErrFieldNotExists},
+		{`{"a":{"b":1}}`, "a.b", ErrNotAnArray},
+		{`{"a":[[1]]}`, "a[1]", ErrIndexOutOfBounds},
+		{`{"a":[[1]]}`, "a[0][0]", ErrNotAnArray},
+		{`{"a":[1,`, "a", nil},
+		{`{"a":[{"b":`, "a", io.ErrUnexpectedEOF},
+		{`{"x":[1,{"y":`, "a", io.ErrUnexpectedEOF},
+		{``, "", io.ErrUnexpectedEOF},
+	} {
+		stream := NewArrayStream(strings.NewReader(c.text), c.path)
+		var err error
+		for err == nil {
+			_, err = stream.NextValue()
+		}
+		if err != c.err && (c.err != nil || err == io.EOF) {
+			t.Errorf("%s %q: got %v, want %v", c.text, c.path, err, c.err)
+		}
+		if _, again := stream.NextValue(); again != err {
+			t.Errorf("%s %q: got %v after an error", c.text, c.path, again)
+		}
+	}
+
+	stream := NewArrayStream(strings.NewReader(`[1,}`), "")
+	stream.NextValue()
+	if _, err := stream.NextValue(); err == nil || err == io.EOF {
+		t.Fatalf("got %v, want a syntax error", err)
+	}
+}
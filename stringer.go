@@ -1,8 +1,8 @@
 package EasyJSON
 
 import (
+	"unicode/utf16"
 	"unicode/utf8"
-	"strings"
 )
 
 var hex = "0123456789abcdef"
@@ -225,40 +225,68 @@ var htmlSafeSet = [utf8.RuneSelf]bool{
 
 // NOTE: keep in sync with stringBytes below.
 func Stringer(s string, escapeHTML bool) string {
-	var sb strings.Builder
-	sb.WriteByte('"')
+	return string(appendString(nil, s, escapeOptions{escapeHTML: escapeHTML}))
+}
+
+/*
+字符串转义的选项
+   escapeHTML  -- 见Stringer
+   asciiOnly   -- 所有非ASCII字符都转义为\uXXXX，超出基本多文种平面的字符转义为UTF-16代理对
+   escapeSlash -- /(斜杠)转义为\/
+   canonical   -- 按RFC 8785 (JCS)的要求只转义必须转义的字符:
+                  双引号、反斜杠和控制字符，\b \t \n \f \r使用简写形式，
+                  U+2028、U+2029原样输出
+ */
+type escapeOptions struct {
+	escapeHTML  bool
+	asciiOnly   bool
+	escapeSlash bool
+	canonical   bool
+}
+
+/*
+把s转义后加上双引号，追加到dst的末尾，返回追加后的切片
+ */
+func appendString(dst []byte, s string, opts escapeOptions) []byte {
+	dst = append(dst, '"')
 	start := 0
 	for i := 0; i < len(s); {
 		if b := s[i]; b < utf8.RuneSelf {
-			if htmlSafeSet[b] || (!escapeHTML && safeSet[b]) {
+			if (htmlSafeSet[b] || (!opts.escapeHTML && safeSet[b])) && !(opts.escapeSlash && b == '/') {
 				i++
 				continue
 			}
 			if start < i {
-				sb.WriteString(s[start:i])
+				dst = append(dst, s[start:i]...)
 			}
 			switch b {
-			case '\\', '"':
-				sb.WriteByte('\\')
-				sb.WriteByte(b)
+			case '\\', '"', '/':
+				dst = append(dst, '\\', b)
 			case '\n':
-				sb.WriteByte('\\')
-				sb.WriteByte('n')
+				dst = append(dst, '\\', 'n')
 			case '\r':
-				sb.WriteByte('\\')
-				sb.WriteByte('r')
+				dst = append(dst, '\\', 'r')
 			case '\t':
-				sb.WriteByte('\\')
-				sb.WriteByte('t')
+				dst = append(dst, '\\', 't')
+			case '\b', '\f':
+				if opts.canonical {
+					if b == '\b' {
+						dst = append(dst, '\\', 'b')
+					} else {
+						dst = append(dst, '\\', 'f')
+					}
+					break
+				}
+				dst = append(dst, `\u00`...)
+				dst = append(dst, hex[b>>4], hex[b&0xF])
 			default:
 				// This encodes bytes < 0x20 except for \t, \n and \r.
 				// If escapeHTML is set, it also escapes <, >, and &
 				// because they can lead to security holes when
 				// user-controlled strings are rendered into JSON
 				// and served to some browsers.
-				sb.WriteString(`\u00`)
-				sb.WriteByte(hex[b>>4])
-				sb.WriteByte(hex[b&0xF])
+				dst = append(dst, `\u00`...)
+				dst = append(dst, hex[b>>4], hex[b&0xF])
 			}
 			i++
 			start = i
@@ -267,9 +295,9 @@ func Stringer(s string, escapeHTML bool) string {
 		c, size := utf8.DecodeRuneInString(s[i:])
 		if c == utf8.RuneError && size == 1 {
 			if start < i {
-				sb.WriteString(s[start:i])
+				dst = append(dst, s[start:i]...)
 			}
-			sb.WriteString(`\ufffd`)
+			dst = append(dst, `\ufffd`...)
 			i += size
 			start = i
 			continue
@@ -279,14 +307,29 @@ func Stringer(s string, escapeHTML bool) string {
 		// They are both technically valid characters in JSON strings,
 		// but don't work in JSONP, which has to be evaluated as JavaScript,
 		// and can lead to security holes there. It is valid JSON to
-		// escape them, so we do so unconditionally.
+		// escape them, so we do so unless canonical output is requested.
 		// See http://timelessrepo.com/json-isnt-a-javascript-subset for discussion.
-		if c == '\u2028' || c == '\u2029' {
+		if (c == '\u2028' || c == '\u2029') && !opts.canonical {
+			if start < i {
+				dst = append(dst, s[start:i]...)
+			}
+			dst = append(dst, `\u202`...)
+			dst = append(dst, hex[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		if opts.asciiOnly {
 			if start < i {
-				sb.WriteString(s[start:i])
+				dst = append(dst, s[start:i]...)
+			}
+			if c > 0xFFFF {
+				r1, r2 := utf16.EncodeRune(c)
+				dst = appendUnicodeEscape(dst, r1)
+				dst = appendUnicodeEscape(dst, r2)
+			} else {
+				dst = appendUnicodeEscape(dst, c)
 			}
-			sb.WriteString(`\u202`)
-			sb.WriteByte(hex[c&0xF])
 			i += size
 			start = i
 			continue
@@ -294,10 +337,16 @@ func Stringer(s string, escapeHTML bool) string {
 		i += size
 	}
 	if start < len(s) {
-		sb.WriteString(s[start:])
+		dst = append(dst, s[start:]...)
 	}
-	sb.WriteByte('"')
+	dst = append(dst, '"')
 
-	return sb.String()
+	return dst
 }
 
+/*
+把c转义为\uXXXX，追加到dst的末尾
+ */
+func appendUnicodeEscape(dst []byte, c rune) []byte {
+	return append(dst, '\\', 'u', hex[c>>12&0xF], hex[c>>8&0xF], hex[c>>4&0xF], hex[c&0xF])
+}
@@ -0,0 +1,70 @@
+package EasyJSON
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEscapeOptions(t *testing.T) {
+	const text = `{"s":"<a href=\"/x\">&</a>","u":"\u00e9\ud83d\ude00","l":"\u2028"}`
+	for _, c := range []struct {
+		set  func(doc *EasyJSON)
+		want string
+	}{
+		{func(doc *EasyJSON) {}, `{"s":"<a href=\"/x\">&</a>","u":"é😀","l":"\u2028"}`},
+		{func(doc *EasyJSON) { doc.SetEscapeHTML(true) }, `{"s":"\u003ca href=\"/x\"\u003e\u0026\u003c/a\u003e","u":"é😀","l":"\u2028"}`},
+		{func(doc *EasyJSON) { doc.SetASCIIOnly(true) }, `{"s":"<a href=\"/x\">&</a>","u":"\u00e9\ud83d\ude00","l":"\u2028"}`},
+		{func(doc *EasyJSON) { doc.SetEscapeSlash(true) }, `{"s":"<a href=\"\/x\">&<\/a>","u":"é😀","l":"\u2028"}`},
+	} {
+		doc, _ := Parse(text)
+		c.set(doc)
+		if got := doc.String(); got != c.want {
+			t.Errorf("String: got %s, want %s", got, c.want)
+		}
+		if got := string(doc.AppendJSON(nil)); got != c.want {
+			t.Errorf("AppendJSON: got %s, want %s", got, c.want)
+		}
+		var buf bytes.Buffer
+		if _, err := doc.WriteTo(&buf); err != nil || buf.String() != c.want {
+			t.Errorf("WriteTo: got %s, %v, want %s", buf.String(), err, c.want)
+		}
+		if got := doc.Clone().String(); got != c.want {
+			t.Errorf("Clone: got %s, want %s", got, c.want)
+		}
+
+		// 输出选项不影响数据
+		other, _ := Parse(doc.String())
+		if !other.Equal(doc) {
+			t.Errorf("%s does not round trip", doc)
+		}
+	}
+}
+
+func TestEscapeOptionsFrozen(t *testing.T) {
+	doc, _ := Parse(`{"s":"</é>"}`)
+	frozen := doc.Freeze()
+	frozen.SetEscapeHTML(true)
+	frozen.SetASCIIOnly(true)
+	frozen.SetEscapeSlash(true)
+	if got := frozen.String(); got != `{"s":"</é>"}` {
+		t.Fatalf("frozen document options changed: %s", got)
+	}
+}
+
+func TestStringer(t *testing.T) {
+	for _, c := range []struct {
+		s          string
+		escapeHTML bool
+		want       string
+	}{
+		{"a\"b\\c\n\x01", false, `"a\"b\\c\n\u0001"`},
+		{"<&>", false, `"<&>"`},
+		{"<&>", true, `"\u003c\u0026\u003e"`},
+		{"a\xffb", false, `"a\ufffdb"`},
+	} {
+		if got := Stringer(c.s, c.escapeHTML); got != c.want {
+			t.Errorf("Stringer(%q, %v): got %s, want %s", c.s, c.escapeHTML, got, c.want)
+		}
+	}
+}
+
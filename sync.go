@@ -0,0 +1,195 @@
+package EasyJSON
+
+import (
+	"sync"
+)
+
+/*
+并发安全的EasyJSON
+EasyJSON底层的map和切片没有加锁，多个goroutine同时读写会导致
+"concurrent map read and map write"，这时应该使用SyncEasyJSON:
+   读操作(Get*、Opt*、String等)使用读锁，可以并发执行
+   写操作(Set、Append、Delete、Update)使用写锁
+返回的JSON对象和JSON数组都是拷贝，不与SyncEasyJSON共享数据
+*/
+type SyncEasyJSON struct {
+	mu  sync.RWMutex
+	doc *EasyJSON
+}
+
+/*
+返回包装了EasyJSON的SyncEasyJSON
+之后应该只通过SyncEasyJSON访问这个EasyJSON
+*/
+func (easyJSON *EasyJSON) Synchronized() *SyncEasyJSON {
+	easyJSON.materialize()  // 懒解析的文档在读取时也会修改自身
+	return &SyncEasyJSON{doc: easyJSON}
+}
+
+func (s *SyncEasyJSON) Get(path string) (interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.Get(path)
+}
+
+func (s *SyncEasyJSON) Opt(path string, defaultValue interface{}) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.Opt(path, defaultValue)
+}
+
+func (s *SyncEasyJSON) Exists(path string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.Exists(path)
+}
+
+func (s *SyncEasyJSON) GetInt64(path string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.GetInt64(path)
+}
+
+func (s *SyncEasyJSON) OptInt64(path string, defaultValue int64) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.OptInt64(path, defaultValue)
+}
+
+func (s *SyncEasyJSON) GetFloat64(path string) (float64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.GetFloat64(path)
+}
+
+func (s *SyncEasyJSON) OptFloat64(path string, defaultValue float64) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.OptFloat64(path, defaultValue)
+}
+
+func (s *SyncEasyJSON) GetBoolean(path string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.GetBoolean(path)
+}
+
+func (s *SyncEasyJSON) OptBoolean(path string, defaultValue bool) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.OptBoolean(path, defaultValue)
+}
+
+func (s *SyncEasyJSON) GetString(path string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.GetString(path)
+}
+
+func (s *SyncEasyJSON) OptString(path string, defaultValue string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.OptString(path, defaultValue)
+}
+
+/*
+返回path处JSON对象的拷贝
+*/
+func (s *SyncEasyJSON) GetObject(path string) (*EasyJSON, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, err := s.doc.GetObject(path)
+	if err != nil {
+		return nil, err
+	}
+	return value.Clone(), nil
+}
+
+/*
+返回path处JSON数组的拷贝
+*/
+func (s *SyncEasyJSON) GetArray(path string) (*EasyJSON, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, err := s.doc.GetArray(path)
+	if err != nil {
+		return nil, err
+	}
+	return value.Clone(), nil
+}
+
+/*
+在读锁的保护下遍历，callback得到的值都是拷贝，与EasyJSON的Range相同
+callback中不能修改SyncEasyJSON，否则会死锁
+*/
+func (s *SyncEasyJSON) Range(callback func(key interface{}, value interface{})) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.doc.Range(callback)
+}
+
+func (s *SyncEasyJSON) Length() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.Length()
+}
+
+func (s *SyncEasyJSON) String() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.String()
+}
+
+/*
+返回整个文档的拷贝
+*/
+func (s *SyncEasyJSON) Snapshot() *EasyJSON {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.doc.Clone()
+}
+
+/*
+设置path处的值
+value会被拷贝，之后修改value不会影响SyncEasyJSON
+*/
+func (s *SyncEasyJSON) Set(path string, value interface{}) error {
+	value = cloneValue(valueEncoder(value))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doc.Set(path, value)
+}
+
+/*
+在path处的JSON数组末尾追加value
+value会被拷贝，之后修改value不会影响SyncEasyJSON
+*/
+func (s *SyncEasyJSON) Append(path string, value interface{}) error {
+	value = cloneValue(valueEncoder(value))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doc.Append(path, value)
+}
+
+func (s *SyncEasyJSON) Delete(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doc.Delete(path)
+}
+
+/*
+在写锁的保护下执行fn，与EasyJSON的Transaction相同:
+tx是文档的拷贝，fn中对tx的修改一起生效，fn返回error时所有修改都被丢弃
+修改会记录到修改日志并通知Watch，Watch的callback在写锁中执行，不能再访问s
+只读的EasyJSON(见Freeze)返回ErrImmutable
+fn返回后不应该再使用tx
+*/
+func (s *SyncEasyJSON) Update(fn func(tx *EasyJSON) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doc.Transaction(fn)
+}
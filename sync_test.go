@@ -0,0 +1,143 @@
+package EasyJSON
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+/*
+多个goroutine同时读写同一个SyncEasyJSON，用go test -race运行
+*/
+func TestSyncEasyJSONConcurrent(t *testing.T) {
+	doc, _ := Parse(`{"counter":0,"name":"x","list":[],"nested":{"n":0}}`)
+	s := doc.Synchronized()
+
+	const writers, readers, rounds = 4, 4, 200
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				s.Set("nested.n", j)
+				s.Set("nested.last", Object("writer", i))
+				s.Append("list", j)
+				s.Update(func(tx *EasyJSON) error {
+					n, _ := tx.GetInt64("counter")
+					return tx.Set("counter", n+1)
+				})
+				if j%10 == 0 {
+					s.Delete("list[0]")
+				}
+			}
+		}(i)
+	}
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < rounds; j++ {
+				s.Get("nested")
+				s.GetInt64("counter")
+				s.GetString("name")
+				s.Exists("nested.last")
+				s.Length()
+				_ = s.String()
+				s.Range(func(key interface{}, value interface{}) {})
+
+				// 返回的是拷贝，修改它不影响SyncEasyJSON
+				if nested, err := s.GetObject("nested"); err == nil {
+					nested.Set("n", -1)
+				}
+				if list, err := s.GetArray("list"); err == nil {
+					list.Append("", -1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n, _ := s.GetInt64("counter"); n != writers*rounds {
+		t.Fatalf("counter: got %d, want %d", n, writers*rounds)
+	}
+	if n := s.Snapshot().OptArray("list", nil).Length(); n != writers*rounds-writers*rounds/10 {
+		t.Fatalf("list length: got %d, want %d", n, writers*rounds-writers*rounds/10)
+	}
+}
+
+func TestSyncEasyJSONUpdate(t *testing.T) {
+	doc, _ := Parse(`{"a":1,"b":[1,2]}`)
+	s := doc.Synchronized()
+
+	err := s.Update(func(tx *EasyJSON) error {
+		tx.Set("a", 2)
+		tx.Delete("b")
+		return errors.New("rollback")
+	})
+	if err == nil || s.String() != `{"a":1,"b":[1,2]}` {
+		t.Fatalf("failed Update was applied: %v %s", err, s.String())
+	}
+
+	if err := s.Update(func(tx *EasyJSON) error { return tx.Delete("b[0]") }); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String(); got != `{"a":1,"b":[2]}` {
+		t.Fatalf("got %s", got)
+	}
+}
+
+func TestSyncEasyJSONValueCopied(t *testing.T) {
+	s := Object().Synchronized()
+	value := Object("n", 1)
+	s.Set("v", value)
+	value.Set("n", 2)
+
+	if n, _ := s.GetInt64("v.n"); n != 1 {
+		t.Fatalf("Set shared data with its argument: v.n = %d", n)
+	}
+}
+
+func TestSyncEasyJSONUpdateFrozen(t *testing.T) {
+	doc, _ := Parse(`{"a":1}`)
+	s := doc.Freeze().Synchronized()
+
+	err := s.Update(func(tx *EasyJSON) error {
+		return tx.Set("a", 2)
+	})
+	if err != ErrImmutable {
+		t.Fatalf("got %v, want ErrImmutable", err)
+	}
+	if got := s.String(); got != `{"a":1}` {
+		t.Fatalf("frozen document changed: %s", got)
+	}
+}
+
+func TestSyncEasyJSONUpdateObserved(t *testing.T) {
+	doc, _ := Parse(`{"a":1,"b":{"c":1}}`)
+	doc.EnableJournal()
+	var changes []Change
+	doc.Watch("b.*", func(change Change) {
+		changes = append(changes, change)
+	})
+	s := doc.Synchronized()
+
+	err := s.Update(func(tx *EasyJSON) error {
+		tx.Set("a", 2)
+		return tx.Set("b.c", 2)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "b.c" || changes[0].NewValue != 2 {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	// 整个Update作为一次修改，可以一次Undo
+	if err := doc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.String(); got != `{"a":1,"b":{"c":1}}` {
+		t.Fatalf("got %s after Undo", got)
+	}
+}
@@ -0,0 +1,152 @@
+package EasyJSON
+
+type walkOp int
+
+const (
+	walkContinue = walkOp(iota)
+	walkSkip
+	walkStop
+	walkDelete
+	walkReplace
+)
+
+/*
+Walk回调函数的返回值，决定遍历如何继续
+*/
+type WalkAction struct {
+	op    walkOp
+	value interface{}
+}
+
+var (
+	WALK_CONTINUE = WalkAction{op: walkContinue}  // 继续遍历，如果是JSON对象或JSON数组，会遍历其中的元素
+	WALK_SKIP     = WalkAction{op: walkSkip}      // 不遍历其中的元素，继续遍历下一个节点
+	WALK_STOP     = WalkAction{op: walkStop}      // 停止遍历
+	WALK_DELETE   = WalkAction{op: walkDelete}    // 删除当前节点，继续遍历下一个节点
+)
+
+/*
+把当前节点替换为value，继续遍历下一个节点(不遍历value中的元素)
+value可以为任意类型，与Set相同
+*/
+func WalkReplace(value interface{}) WalkAction {
+	return WalkAction{op: walkReplace, value: value}
+}
+
+/*
+深度优先遍历整个文档(不包括最外层)，先访问节点本身，再访问其中的元素
+callback: 回调函数
+   path  -- 节点的路径，与Get、Set的path语法相同
+   value -- 节点的值，JSON对象和JSON数组以*EasyJSON表示(与原文档共享数据)，其它值原样返回
+   kind  -- 节点的JSON类型
+   返回值决定遍历如何继续，见WalkAction
+删除JSON数组的元素后，后面元素的下标随之减小，path总是节点当前的路径
+只读的EasyJSON(见Freeze)不能修改，WALK_DELETE和WalkReplace()等同于WALK_SKIP
+*/
+func (easyJSON *EasyJSON) Walk(callback func(path string, value interface{}, kind Kind) WalkAction) {
+	easyJSON.materialize()
+	w := &walker{callback: callback, doc: easyJSON}
+	if easyJSON.jsonType == JSON_TYPE_OBJECT {
+		w.walkObject("", easyJSON.m)
+	} else if easyJSON.jsonType == JSON_TYPE_ARRAY {
+		// 删除了元素时才放回，只读的EasyJSON不会被写入
+		if a := w.walkArray("", easyJSON.a); len(a) != len(easyJSON.a) {
+			easyJSON.a = a
+		}
+	}
+	if w.changed {
+		easyJSON.recordRoot(w.old)
+	}
+}
+
+type walker struct {
+	callback func(path string, value interface{}, kind Kind) WalkAction
+	stopped  bool
+	doc      *EasyJSON    // 遍历的文档，节点的*EasyJSON与它的只读状态和输出选项相同
+	changed  bool         // 是否删除或替换过节点
+	old      interface{}  // 第一次修改前的文档，见change
+}
+
+/*
+第一次删除或替换节点之前记录文档原来的内容，用于修改日志和Watch
+只读取不修改的遍历不需要拷贝文档
+*/
+func (w *walker) change() {
+	if !w.changed {
+		w.changed = true
+		w.old = w.doc.snapshot()
+	}
+}
+
+/*
+访问一个节点，返回遍历的动作
+如果需要遍历其中的元素，已经遍历完成
+*/
+func (w *walker) visit(path string, value interface{}) WalkAction {
+	action := w.callback(path, wrapValue(value, w.doc), kindOf(value))
+	if action.op == walkDelete || action.op == walkReplace {
+		if w.doc.frozen {
+			return WALK_SKIP
+		}
+		w.change()
+	}
+
+	switch action.op {
+	case walkStop:
+		w.stopped = true
+	case walkContinue:
+		switch v := value.(type) {
+		case *orderedMap:
+			w.walkObject(path, v)
+		case []interface{}:
+			a := w.walkArray(path, v)
+			// 删除了元素，切片已经变化
+			if len(a) != len(v) {
+				return WalkReplace(a)
+			}
+		}
+	case walkReplace:
+		action.value = valueEncoder(action.value)
+	}
+	return action
+}
+
+func (w *walker) walkObject(path string, m *orderedMap) {
+	keys := make([]string, len(m.keys))
+	copy(keys, m.keys)
+
+	for _, k := range keys {
+		action := w.visit(joinPath(path, k), m.m[k])
+		switch action.op {
+		case walkDelete:
+			m.delete(k)
+		case walkReplace:
+			m.set(k, action.value)
+		}
+		if w.stopped {
+			return
+		}
+	}
+}
+
+/*
+返回遍历后的切片，删除了元素时与a不同
+*/
+func (w *walker) walkArray(path string, a []interface{}) []interface{} {
+	for i := 0; i < len(a); {
+		action := w.visit(indexPath(path, i), a[i])
+		switch action.op {
+		case walkDelete:
+			a = append(a[:i], a[i+1:]...)
+		case walkReplace:
+			a[i] = action.value
+			i++
+		default:
+			i++
+		}
+		if w.stopped {
+			break
+		}
+	}
+	return a
+}
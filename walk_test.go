@@ -0,0 +1,72 @@
+package EasyJSON
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	doc, _ := Parse(`{"a":{"secret":"x","b":[1,2,3]},"c":"y"}`)
+	doc.EnableJournal()
+
+	var paths []string
+	doc.Walk(func(path string, value interface{}, kind Kind) WalkAction {
+		paths = append(paths, path)
+		switch {
+		case path == "a.secret":
+			return WalkReplace("***")
+		case kind == KIND_NUMBER && value == float64(2):
+			return WALK_DELETE
+		case path == "c":
+			return WALK_STOP
+		}
+		return WALK_CONTINUE
+	})
+
+	want := []string{"a", "a.secret", "a.b", "a.b[0]", "a.b[1]", "a.b[1]", "c"}
+	if len(paths) != len(want) {
+		t.Fatalf("got paths %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("got paths %v, want %v", paths, want)
+		}
+	}
+	if got := doc.String(); got != `{"a":{"secret":"***","b":[1,3]},"c":"y"}` {
+		t.Fatalf("got %s", got)
+	}
+
+	// 整个Walk作为一次修改
+	if err := doc.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":{"secret":"x","b":[1,2,3]},"c":"y"}` {
+		t.Fatalf("got %s after Undo", got)
+	}
+}
+
+/*
+开启修改日志时，只读取不修改的Walk不应该拷贝整个文档
+*/
+func TestWalkReadOnlyNoSnapshot(t *testing.T) {
+	doc := Array()
+	for i := 0; i < 1000; i++ {
+		doc.Append("", Object("id", i, "name", strconv.Itoa(i)))
+	}
+	count := func() float64 {
+		return testing.AllocsPerRun(10, func() {
+			doc.Walk(func(path string, value interface{}, kind Kind) WalkAction {
+				return WALK_SKIP
+			})
+		})
+	}
+
+	plain := count()
+	doc.EnableJournal()
+	if observed := count(); observed > plain+10 {
+		t.Fatalf("read-only Walk with a journal: %v allocs, without: %v", observed, plain)
+	}
+	if doc.CanUndo() {
+		t.Fatal("read-only Walk was recorded in the journal")
+	}
+}
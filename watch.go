@@ -0,0 +1,168 @@
+package EasyJSON
+
+type watcher struct {
+	pattern  []string
+	callback func(Change)
+}
+
+/*
+监听pathPattern处的变化，返回取消监听的函数
+pathPattern: 与Get、Set的path语法相同，另外可以使用通配符
+   "*"   -- 匹配任意字段名，例如 "servers.*.port"
+   "[*]" -- 匹配任意数组下标，例如 "users[*].name"
+   字段名就是"*"时写作 ["*"]
+   ""    -- 匹配整个文档
+通过Set、Append、Delete、ApplyPatch、MergePatch、Merge、Walk、Undo、Redo进行修改后，
+以下变化都会调用callback:
+   1. 变化的路径与pathPattern匹配
+   2. 变化发生在pathPattern的子节点中，例如监听"server"时修改了"server.port"
+   3. 变化发生在pathPattern的上级节点，例如监听"server.port"时替换或删除了"server"
+Change.Path是实际发生变化的路径，值都是拷贝
+ApplyPatch、MergePatch、Merge、Walk逐项比较修改前后的文档，对每一处实际的变化分别调用callback
+callback在修改完成后同步调用，在callback中修改文档会再次触发监听
+通过GetObject、GetArray返回的视图进行的修改不会触发监听
+只读的EasyJSON(见Freeze)不会变化，pathPattern格式不正确时也不会匹配任何变化，
+这两种情况都不记录callback，返回的函数什么也不做
+*/
+func (easyJSON *EasyJSON) Watch(pathPattern string, callback func(Change)) func() {
+	pattern, err := splitPath(pathPattern, true)
+	if easyJSON.frozen || err != nil {
+		return func() {}
+	}
+	w := &watcher{pattern: pattern, callback: callback}
+	easyJSON.watchers = append(easyJSON.watchers, w)
+
+	return func() {
+		for i, v := range easyJSON.watchers {
+			if v == w {
+				easyJSON.watchers = append(easyJSON.watchers[:i:i], easyJSON.watchers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+/*
+在fn中对tx的修改一起生效，修改完成后才统一通知Watch
+tx是文档的拷贝，fn返回error时所有修改都被丢弃，也不会通知Watch
+开启了修改日志时，整个Transaction作为一次修改记录，可以一次Undo
+fn返回后不应该再使用tx
+*/
+func (easyJSON *EasyJSON) Transaction(fn func(tx *EasyJSON) error) error {
+	if easyJSON.frozen {
+		return ErrImmutable
+	}
+
+	tx := easyJSON.Clone()
+	tx.pending = &[]Change{}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// tx是拷贝，原来的最外层没有被修改
+	old := easyJSON.root()
+	easyJSON.setRoot(tx.root())
+	if len(*tx.pending) == 0 {
+		return nil
+	}
+
+	kind := CHANGE_MODIFIED
+	if kindOf(old) != kindOf(easyJSON.root()) {
+		kind = CHANGE_TYPE_CHANGED
+	}
+	easyJSON.record(Change{Path: "", Kind: kind, OldValue: old, NewValue: easyJSON.root()})
+	easyJSON.notify(*tx.pending...)
+	return nil
+}
+
+/*
+通知与变化匹配的Watch
+change中的值可以是底层的数据表示，每个callback得到各自的拷贝
+在Transaction中时只暂存起来
+*/
+func (easyJSON *EasyJSON) notify(changes ...Change) {
+	if easyJSON.pending != nil {
+		for _, change := range changes {
+			*easyJSON.pending = append(*easyJSON.pending, exportChange(change))
+		}
+		return
+	}
+	if len(easyJSON.watchers) == 0 {
+		return
+	}
+
+	// callback中可能取消监听，使用拷贝遍历
+	watchers := make([]*watcher, len(easyJSON.watchers))
+	copy(watchers, easyJSON.watchers)
+	for _, change := range changes {
+		names := patternNames(change.Path)
+		for _, w := range watchers {
+			if matchPattern(w.pattern, names) {
+				w.callback(exportChange(change))
+			}
+		}
+	}
+}
+
+/*
+通知修改日志中的一条记录，undo为true时通知的是撤销这条记录产生的变化
+*/
+func (easyJSON *EasyJSON) notifyEntry(entry *journalEntry, undo bool) {
+	if len(easyJSON.watchers) == 0 && easyJSON.pending == nil {
+		return
+	}
+
+	change := entry.change
+	if undo {
+		change.OldValue, change.NewValue = change.NewValue, change.OldValue
+		switch change.Kind {
+		case CHANGE_ADDED:
+			change.Kind = CHANGE_REMOVED
+		case CHANGE_REMOVED:
+			change.Kind = CHANGE_ADDED
+		}
+	}
+
+	if change.Path != "" {
+		easyJSON.notify(change)
+		return
+	}
+	d := &differ{}
+	d.diffValue("", change.OldValue, change.NewValue)
+	easyJSON.notify(d.changes...)
+}
+
+func exportChange(change Change) Change {
+	change.OldValue = exportValue(change.OldValue)
+	change.NewValue = exportValue(change.NewValue)
+	return change
+}
+
+func patternNames(pattern string) []string {
+	names, _ := splitPath(pattern, true)  // Watch已经检查过格式
+	return names
+}
+
+/*
+pattern与path的较短者是否为另一个的前缀
+"*"匹配任意字段名，"[*]"匹配任意数组下标
+*/
+func matchPattern(pattern, path []string) bool {
+	for i := 0; i < len(pattern) && i < len(path); i++ {
+		switch p := pattern[i]; p {
+		case "*":
+			if path[i][0] == '[' {
+				return false
+			}
+		case "[*]":
+			if path[i][0] != '[' {
+				return false
+			}
+		default:
+			if p != path[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package EasyJSON
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWatchPatterns(t *testing.T) {
+	doc, _ := Parse(`{"servers":{"a":{"port":1},"b":{"port":2}},"users":[{"name":"x"}],"*":0}`)
+
+	var got []string
+	watch := func(pattern string) {
+		doc.Watch(pattern, func(change Change) {
+			got = append(got, pattern+" "+change.Path)
+		})
+	}
+	for _, pattern := range []string{"servers.*.port", "users[*].name", "servers", "servers.a.port.x", `["*"]`, "", "users.*"} {
+		watch(pattern)
+	}
+
+	for _, c := range []struct {
+		change func() error
+		want   []string
+	}{
+		{func() error { return doc.Set("servers.b.port", 3) },
+			[]string{"servers.*.port servers.b.port", "servers servers.b.port", " servers.b.port"}},
+		{func() error { return doc.Set("users[0].name", "y") },
+			[]string{"users[*].name users[0].name", " users[0].name"}},
+		{func() error { return doc.Delete("servers.a") },
+			[]string{"servers.*.port servers.a", "servers servers.a", "servers.a.port.x servers.a", " servers.a"}},
+		{func() error { return doc.Set(`["*"]`, 1) },
+			[]string{`["*"] ["*"]`, ` ["*"]`}},
+	} {
+		got = nil
+		if err := c.change(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("got %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestWatchChange(t *testing.T) {
+	doc, _ := Parse(`{"a":{"b":1,"c":[1]}}`)
+	doc.EnableJournal()
+
+	var changes []Change
+	doc.Watch("a", func(change Change) {
+		changes = append(changes, change)
+	})
+
+	doc.MergePatch(Object("a", Object("b", 2, "c", nil, "d", true)))
+	want := []Change{
+		{Path: "a.b", Kind: CHANGE_MODIFIED, OldValue: float64(1), NewValue: 2},
+		{Path: "a.c", Kind: CHANGE_REMOVED, OldValue: []interface{}{float64(1)}},
+		{Path: "a.d", Kind: CHANGE_ADDED, NewValue: true},
+	}
+	if !changesEqual(changes, want) {
+		t.Fatalf("got %v, want %v", changes, want)
+	}
+
+	// 值是拷贝
+	changes[1].OldValue.([]interface{})[0] = "x"
+	if doc.Journal()[0].OldValue.(map[string]interface{})["a"].(map[string]interface{})["c"].([]interface{})[0] != float64(1) {
+		t.Fatal("callback shares data with the journal")
+	}
+
+	changes = nil
+	doc.Undo()
+	want = []Change{
+		{Path: "a.b", Kind: CHANGE_MODIFIED, OldValue: 2, NewValue: 1},
+		{Path: "a.d", Kind: CHANGE_REMOVED, OldValue: true},
+		{Path: "a.c", Kind: CHANGE_ADDED, NewValue: []interface{}{1}},
+	}
+	if !changesEqual(changes, want) {
+		t.Fatalf("Undo: got %v, want %v", changes, want)
+	}
+}
+
+func TestWatchUnsubscribe(t *testing.T) {
+	doc := Object("a", 1)
+
+	count := 0
+	var stop func()
+	stop = doc.Watch("a", func(Change) {
+		count++
+		stop()
+	})
+	other := 0
+	doc.Watch("a", func(Change) { other++ })
+
+	doc.Set("a", 2)
+	doc.Set("a", 3)
+	stop()
+	if count != 1 || other != 2 {
+		t.Fatalf("got %d, %d calls", count, other)
+	}
+
+	// 格式不正确的pattern、只读的文档不记录callback
+	for _, stop := range []func(){
+		doc.Watch("a[", func(Change) { t.Fatal("invalid pattern matched") }),
+		doc.Freeze().Watch("a", func(Change) { t.Fatal("frozen document changed") }),
+	} {
+		stop()
+	}
+	doc.Set("a", 4)
+}
+
+func TestTransaction(t *testing.T) {
+	doc, _ := Parse(`{"a":1,"b":[1]}`)
+	doc.EnableJournal()
+
+	var paths []string
+	doc.Watch("", func(change Change) {
+		paths = append(paths, change.Path)
+	})
+
+	err := doc.Transaction(func(tx *EasyJSON) error {
+		tx.Set("a", 2)
+		tx.Append("b", 2)
+		if len(paths) != 0 {
+			t.Fatal("notified before the transaction finished")
+		}
+		if got := doc.String(); got != `{"a":1,"b":[1]}` {
+			t.Fatalf("document changed before the transaction finished: %s", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != `{"a":2,"b":[1,2]}` {
+		t.Fatalf("got %s", got)
+	}
+	if !reflect.DeepEqual(paths, []string{"a", "b[1]"}) {
+		t.Fatalf("got %q", paths)
+	}
+	if len(doc.Journal()) != 1 {
+		t.Fatalf("got %d journal entries", len(doc.Journal()))
+	}
+
+	// fn返回error时丢弃所有修改
+	paths = nil
+	failed := errors.New("failed")
+	err = doc.Transaction(func(tx *EasyJSON) error {
+		tx.Delete("a")
+		return failed
+	})
+	if err != failed || doc.String() != `{"a":2,"b":[1,2]}` || paths != nil || len(doc.Journal()) != 1 {
+		t.Fatalf("failed transaction applied: %v, %s, %q", err, doc, paths)
+	}
+
+	// 没有修改时不记录
+	doc.Transaction(func(tx *EasyJSON) error { return nil })
+	if len(doc.Journal()) != 1 {
+		t.Fatal("empty transaction recorded")
+	}
+
+	if err := doc.Freeze().Transaction(func(tx *EasyJSON) error { return nil }); err != ErrImmutable {
+		t.Fatalf("got %v, want ErrImmutable", err)
+	}
+}
+
+func changesEqual(a, b []Change) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Path != b[i].Path || a[i].Kind != b[i].Kind ||
+			!valueEqual(a[i].OldValue, b[i].OldValue) || !valueEqual(a[i].NewValue, b[i].NewValue) {
+			return false
+		}
+	}
+	return true
+}